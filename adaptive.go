@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// adaptiveInterval backs the request interval off exponentially while
+// cycles keep failing, resetting to the base interval on the first success
+type adaptiveInterval struct {
+	mu      sync.Mutex
+	base    time.Duration
+	max     time.Duration
+	current time.Duration
+}
+
+// newAdaptiveInterval creates an adaptive interval starting at base and
+// never exceeding max
+func newAdaptiveInterval(base, max time.Duration) *adaptiveInterval {
+	if max < base {
+		max = base
+	}
+	return &adaptiveInterval{base: base, max: max, current: base}
+}
+
+// record updates the interval after a cycle and returns the new interval
+func (a *adaptiveInterval) record(success bool) time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if success {
+		a.current = a.base
+		return a.current
+	}
+
+	if a.current < a.max {
+		a.current *= 2
+		if a.current > a.max {
+			a.current = a.max
+		}
+	}
+	return a.current
+}
+
+// setBase changes the base interval, e.g. after PUT /interval, and resets
+// any backoff
+func (a *adaptiveInterval) setBase(base time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.base = base
+	if a.max < base {
+		a.max = base
+	}
+	a.current = base
+}
+
+// intervalKey is the context key for the effective request interval
+type intervalKey struct{}
+
+// withInterval returns a context carrying the effective request interval
+func withInterval(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, intervalKey{}, d)
+}
+
+// intervalFromContext returns the effective request interval carried by
+// ctx, falling back to the -interval flag
+func intervalFromContext(ctx context.Context) time.Duration {
+	if d, ok := ctx.Value(intervalKey{}).(time.Duration); ok {
+		return d
+	}
+	return *interval
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdaptiveInterval(t *testing.T) {
+	a := newAdaptiveInterval(time.Second, 5*time.Second)
+
+	// Consecutive failures back off exponentially up to the cap
+	expected := []time.Duration{2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, want := range expected {
+		if got := a.record(false); got != want {
+			t.Errorf("record(false) #%d = %s, expected %s", i+1, got, want)
+		}
+	}
+
+	// The first success resets to the base interval
+	if got := a.record(true); got != time.Second {
+		t.Errorf("record(true) = %s, expected %s", got, time.Second)
+	}
+
+	// A new base resets the backoff
+	a.record(false)
+	a.setBase(3 * time.Second)
+	if got := a.record(false); got != 5*time.Second {
+		t.Errorf("record(false) after setBase = %s, expected %s", got, 5*time.Second)
+	}
+}
@@ -0,0 +1,755 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"tracer-test/pkg/health"
+	"tracer-test/pkg/httpclient"
+	"tracer-test/pkg/logger"
+	"tracer-test/pkg/statsd"
+	"tracer-test/pkg/tracer"
+
+	"github.com/robfig/cron/v3"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/zap"
+)
+
+// Settings holds everything needed to build an App
+type Settings struct {
+	URL      string
+	Probe    string
+	Interval time.Duration
+	// Schedule, when set, runs cycles on a cron expression instead of every
+	// Interval. Runtime interval changes and AdaptiveInterval are unavailable.
+	Schedule    string
+	ServiceName string
+	// Environment and Region tag every span and log entry as
+	// deployment.environment and cloud.region. Empty values are omitted.
+	Environment string
+	Region      string
+	// Synthetic flags every root span and the resource as synthetic
+	// monitoring traffic
+	Synthetic bool
+	// IncludeVCSInfo records the commit the binary was built from on the
+	// trace resource
+	IncludeVCSInfo bool
+	// Propagators lists the trace context formats sent to targets, e.g.
+	// "b3multi" or "tracecontext,jaeger". Empty uses W3C trace context.
+	Propagators string
+	// AdaptiveInterval backs the interval off exponentially, up to
+	// MaxInterval, while cycles keep failing
+	AdaptiveInterval bool
+	MaxInterval      time.Duration
+	// RampUp, when positive, starts at RampStartInterval (default ten times
+	// Interval) and shortens the interval linearly to Interval over this
+	// long, easing into load
+	RampUp            time.Duration
+	RampStartInterval time.Duration
+	// MaxBytes, when positive, stops the run once this many response body
+	// bytes have been read. Count and MaxRuntime likewise stop it after
+	// that many cycles or that long. The in-flight cycle always finishes.
+	MaxBytes   int64
+	Count      int
+	MaxRuntime time.Duration
+	// FailFast stops the run with errFailFast on the first failed cycle,
+	// whether it errored or got an unexpected status
+	FailFast bool
+	// BenchmarkOutput, when set, receives a latency and throughput report
+	// when the run ends
+	BenchmarkOutput io.Writer
+	// ManifestFile, when set, receives a JSON record of the configuration,
+	// build, start and end times and aggregate stats when the run ends
+	ManifestFile string
+	// PeerServices maps target URLs to the name of the service behind them,
+	// recorded as peer.service on that URL's spans
+	PeerServices map[string]string
+
+	LogLevel  string
+	LogFormat string
+	// LogFieldNames renames the standard JSON and logfmt log keys
+	LogFieldNames map[string]string
+	// LogBufferSize and LogFlushInterval buffer log output; see logger.Config
+	LogBufferSize    int
+	LogFlushInterval time.Duration
+	// TraceURLTemplate adds a trace_url link to each cycle's log entries;
+	// see logger.Config
+	TraceURLTemplate string
+	// LogOutput receives log entries. Defaults to stdout.
+	LogOutput io.Writer
+
+	OTLPEndpoints      []string
+	OTLPCACert         string
+	DisableOTLP        bool
+	TraceFile          string
+	ExportOnErrorOnly  bool
+	SlowCycleThreshold time.Duration
+	MinSpanDuration    time.Duration
+	// OTLPQueueSize and OTLPBlockOnQueueFull configure each exporter's span
+	// queue; see tracer.Config
+	OTLPQueueSize        int
+	OTLPBlockOnQueueFull bool
+	// RecordExportDuration times every span export as the
+	// otlp_export_duration_seconds histogram on /metrics
+	RecordExportDuration bool
+	// TraceSampler decides which cycles are traced; nil samples them all.
+	// Each cycle's decision is logged as sampled and counted on /metrics.
+	TraceSampler sdktrace.Sampler
+	// DetailSpanSampleRatio is the fraction of requests that get the
+	// transport, DNS and TCP spans; nil records them for every request
+	DetailSpanSampleRatio *float64
+	// MaxSpansPerCycle caps the child spans exported per cycle; see
+	// tracer.Config
+	MaxSpansPerCycle int
+
+	Accept                 string
+	NonErrorStatusCodes    []int
+	ExpectedStatusCodes    []int
+	FailureInjectionRate   float64
+	InjectedLatency        time.Duration
+	InjectedLatencyJitter  time.Duration
+	BearerTokenFile        string
+	CaptureResponseHeaders []string
+	DNSCacheTTL            time.Duration
+	MaxConcurrentDNS       int
+	DialTimeout            time.Duration
+	KeepAlive              time.Duration
+	SocketReadTimeout      time.Duration
+	SocketWriteTimeout     time.Duration
+	MaxRetries             int
+	RetryBackoff           time.Duration
+	ScrubQueryParams       []string
+	ScrubAllQueryParams    bool
+	MinTLSVersion          string
+	CipherSuites           []string
+
+	StatsDAddr string
+	// PushgatewayURL, when set, pushes the metrics to a Prometheus
+	// Pushgateway on shutdown and every PushInterval when positive. PushJob
+	// defaults to ServiceName.
+	PushgatewayURL string
+	PushInterval   time.Duration
+	PushJob        string
+	PushInstance   string
+	// MetricsLabels are added as constant labels to every /metrics series
+	MetricsLabels map[string]string
+
+	HealthPort            int
+	HealthMethods         []string
+	EnableTrigger         bool
+	AdminToken            string
+	ReadyRequiresExport   bool
+	ReadyChecksTarget     bool
+	ReadyWindow           int
+	ReadyFailureThreshold float64
+	// ReadyDelay holds /ready at 503 for this long after startup so
+	// dependencies can warm up
+	ReadyDelay time.Duration
+
+	// CurlOutput, when set, makes Run send a single request and write the
+	// response to it like curl -i, skipping the health server and loop
+	CurlOutput io.Writer
+
+	// TraceIDOutput, when set, receives a "TRACE <trace_id> <url> <status>"
+	// line after each cycle
+	TraceIDOutput io.Writer
+
+	// BuildInfo is reported on the health server's /info endpoint
+	BuildInfo health.BuildInfo
+}
+
+// App wires the logger, tracer, HTTP client and health server together and
+// runs the request loop
+type App struct {
+	settings Settings
+
+	log      *logger.Logger
+	tracer   *tracer.Tracer
+	client   *httpclient.Client
+	health   *health.Server
+	statsd   *statsd.Client
+	ticker   *intervalTicker
+	schedule *scheduleTicker
+	watchdog *watchdog
+	traceIDs *traceIDWriter
+	adaptive *adaptiveInterval
+	ramp     *rampUp
+	bench    *benchmark
+
+	runCycle func(ctx context.Context, requestCount int) requestResult
+	stats    *runStats
+	recent   *recentRequests
+
+	readyTimer    *time.Timer
+	warm          atomic.Bool
+	exportHealthy atomic.Bool
+	maintenance   atomic.Bool
+}
+
+// NewApp validates settings and builds every component. Nothing is started
+// until Run is called.
+func NewApp(settings Settings) (*App, error) {
+	if err := validateProbeMode(settings.Probe); err != nil {
+		return nil, fmt.Errorf("invalid probe: %w", err)
+	}
+	if settings.FailureInjectionRate < 0 || settings.FailureInjectionRate > 1 {
+		return nil, fmt.Errorf("invalid failure injection rate %g, must be between 0 and 1", settings.FailureInjectionRate)
+	}
+	var schedule cron.Schedule
+	if settings.Schedule != "" {
+		if settings.AdaptiveInterval {
+			return nil, errors.New("a schedule cannot be combined with an adaptive interval")
+		}
+		var err error
+		if schedule, err = parseSchedule(settings.Schedule); err != nil {
+			return nil, err
+		}
+	}
+	rampStart := settings.RampStartInterval
+	if settings.RampUp > 0 {
+		if settings.Schedule != "" || settings.AdaptiveInterval {
+			return nil, errors.New("a ramp-up cannot be combined with a schedule or an adaptive interval")
+		}
+		if rampStart == 0 {
+			rampStart = 10 * settings.Interval
+		}
+		if rampStart <= settings.Interval {
+			return nil, fmt.Errorf("ramp start interval %s must be longer than the interval %s", rampStart, settings.Interval)
+		}
+	}
+
+	a := &App{settings: settings, recent: &recentRequests{}}
+
+	// Initialize logger
+	logFields := map[string]string{}
+	if settings.Environment != "" {
+		logFields["deployment.environment"] = settings.Environment
+	}
+	if settings.Region != "" {
+		logFields["cloud.region"] = settings.Region
+	}
+	log, err := logger.New(logger.Config{
+		Level:  settings.LogLevel,
+		Format: settings.LogFormat,
+		Output: settings.LogOutput,
+		Fields: logFields,
+
+		FieldNames:    settings.LogFieldNames,
+		BufferSize:    settings.LogBufferSize,
+		FlushInterval: settings.LogFlushInterval,
+
+		TraceURLTemplate: settings.TraceURLTemplate,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	a.log = log
+
+	// Initialize tracer
+	var endpoint string
+	if len(settings.OTLPEndpoints) > 0 {
+		endpoint = settings.OTLPEndpoints[0]
+	}
+	var observeExport tracer.ExportDurationFunc
+	if settings.RecordExportDuration {
+		observeExport = a.observeExportDuration
+	}
+	a.tracer, err = tracer.New(tracer.Config{
+		Endpoint:           endpoint,
+		Endpoints:          settings.OTLPEndpoints,
+		TLSCACertPath:      settings.OTLPCACert,
+		ServiceName:        settings.ServiceName,
+		Environment:        settings.Environment,
+		Region:             settings.Region,
+		Synthetic:          settings.Synthetic,
+		IncludeVCSInfo:     settings.IncludeVCSInfo,
+		PropagatorFormat:   settings.Propagators,
+		Disabled:           settings.DisableOTLP,
+		FileExportPath:     settings.TraceFile,
+		ExportOnErrorOnly:  settings.ExportOnErrorOnly,
+		SlowCycleThreshold: settings.SlowCycleThreshold,
+		MinSpanDuration:    settings.MinSpanDuration,
+		MaxQueueSize:       settings.OTLPQueueSize,
+		BlockOnQueueFull:   settings.OTLPBlockOnQueueFull,
+		Sampler:            settings.TraceSampler,
+		MaxSpansPerCycle:   settings.MaxSpansPerCycle,
+
+		ObserveExportDuration: observeExport,
+	}, log.Logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize tracer: %w", err)
+	}
+
+	// Initialize HTTP client
+	clientConfig := httpclient.Config{
+		Timeout:                requestTimeout,
+		Accept:                 settings.Accept,
+		NonErrorStatusCodes:    settings.NonErrorStatusCodes,
+		ExpectedStatusCodes:    settings.ExpectedStatusCodes,
+		FailureInjectionRate:   settings.FailureInjectionRate,
+		InjectedLatency:        settings.InjectedLatency,
+		InjectedLatencyJitter:  settings.InjectedLatencyJitter,
+		BearerTokenFile:        settings.BearerTokenFile,
+		CaptureResponseBaggage: settings.CaptureResponseHeaders,
+		DNSCacheTTL:            settings.DNSCacheTTL,
+		MaxConcurrentDNS:       settings.MaxConcurrentDNS,
+		DialTimeout:            settings.DialTimeout,
+		KeepAlive:              settings.KeepAlive,
+		SocketReadTimeout:      settings.SocketReadTimeout,
+		SocketWriteTimeout:     settings.SocketWriteTimeout,
+		MaxRetries:             settings.MaxRetries,
+		DetailSpanSampleRatio:  settings.DetailSpanSampleRatio,
+		RetryBackoff:           settings.RetryBackoff,
+		ScrubQueryParams:       settings.ScrubQueryParams,
+		ScrubAllQueryParams:    settings.ScrubAllQueryParams,
+		MinTLSVersion:          settings.MinTLSVersion,
+		CipherSuites:           settings.CipherSuites,
+	}
+	if err := clientConfig.Validate(); err != nil {
+		_ = a.Shutdown(context.Background())
+		return nil, fmt.Errorf("invalid HTTP client settings: %w", err)
+	}
+	a.client = httpclient.New(clientConfig, log.Logger, a.tracer.GetTracer())
+
+	// Select the per-cycle probe
+	otelTracer := a.tracer.GetTracer()
+	a.runCycle = func(ctx context.Context, requestCount int) requestResult {
+		if name, ok := settings.PeerServices[settings.URL]; ok {
+			ctx = httpclient.WithPeerService(ctx, name)
+		}
+		if a.ticker != nil {
+			ctx = withInterval(ctx, a.ticker.Interval())
+		}
+		return makeRequest(ctx, a.client, log, otelTracer, settings.URL, requestCount)
+	}
+	switch settings.Probe {
+	case probeTCP:
+		a.runCycle = func(ctx context.Context, requestCount int) requestResult {
+			return probeTCPConnect(ctx, a.client.Clock(), log, otelTracer, settings.URL, requestTimeout, requestCount)
+		}
+	case probeDNS:
+		a.runCycle = func(ctx context.Context, requestCount int) requestResult {
+			return probeDNSResolve(ctx, a.client.Clock(), log, otelTracer, settings.URL, requestTimeout, requestCount)
+		}
+	}
+
+	// Initialize optional StatsD emitter
+	if settings.StatsDAddr != "" {
+		a.statsd, err = statsd.New(statsd.Config{Addr: settings.StatsDAddr})
+		if err != nil {
+			_ = a.Shutdown(context.Background())
+			return nil, fmt.Errorf("failed to initialize StatsD emitter: %w", err)
+		}
+	}
+
+	// The request loop runs on a cron schedule or a ticker; the ticker and
+	// stall watchdog can be retuned at runtime
+	var setInterval health.IntervalFunc
+	initial := settings.Interval
+	if schedule != nil {
+		a.schedule = newScheduleTicker(schedule)
+	} else {
+		if settings.RampUp > 0 {
+			a.ramp = newRampUp(rampStart, settings.Interval, settings.RampUp)
+			initial = rampStart
+		}
+		a.ticker = newIntervalTicker(initial)
+		setInterval = func(d time.Duration) {
+			if a.adaptive != nil {
+				a.adaptive.setBase(d)
+			}
+			if a.ramp != nil {
+				// Keep ramping towards the new interval
+				a.ramp.setTarget(d)
+				d = a.ramp.at(a.client.Clock().Now())
+			}
+			a.applyInterval(d)
+			log.Info("Request interval changed", zap.Duration("request_interval", d))
+		}
+	}
+	if settings.AdaptiveInterval {
+		a.adaptive = newAdaptiveInterval(settings.Interval, settings.MaxInterval)
+	}
+	a.watchdog = newWatchdog(a.client.Clock(), stallThreshold(initial, requestTimeout), func(idle time.Duration) {
+		log.Error("Request loop stalled, cancelling in-flight request",
+			zap.Duration("idle", idle))
+		a.health.IncrementLoopStalls()
+	})
+
+	// Initialize health server
+	pushJob := settings.PushJob
+	if pushJob == "" {
+		pushJob = settings.ServiceName
+	}
+	var readyChecks []health.ReadyCheck
+	if settings.ReadyChecksTarget {
+		readyChecks = append(readyChecks, health.ReadyCheck{Name: "target", Check: a.checkTarget})
+	}
+	a.health = health.NewWithConfig(health.Config{
+		Port:          settings.HealthPort,
+		Methods:       settings.HealthMethods,
+		AuthToken:     settings.AdminToken,
+		EnableTrigger: settings.EnableTrigger,
+		Trigger: func(ctx context.Context, override *health.TriggerRequest) health.TriggerResult {
+			if id, ok := health.RequestIDFromContext(ctx); ok {
+				ctx = httpclient.WithRequestID(ctx, id)
+			}
+			if override == nil {
+				return a.trackedCycle(ctx, 0).triggerResult()
+			}
+			a.health.StartRequest()
+			defer a.health.FinishRequest()
+			return makeTriggerRequest(ctx, a.client, log, otelTracer, override)
+		},
+		ReadinessWindow:           settings.ReadyWindow,
+		ReadinessFailureThreshold: settings.ReadyFailureThreshold,
+		ReadyChecks:               readyChecks,
+		SetInterval:               setInterval,
+		SetMaintenance:            a.setMaintenance,
+		PushgatewayURL:            settings.PushgatewayURL,
+		PushInterval:              settings.PushInterval,
+		PushJob:                   pushJob,
+		PushInstance:              settings.PushInstance,
+		BuildInfo:                 settings.BuildInfo,
+		ConstLabels:               settings.MetricsLabels,
+	})
+	if a.ticker != nil {
+		a.health.SetRequestInterval(initial)
+	}
+	a.exportHealthy.Store(true)
+
+	if settings.TraceIDOutput != nil {
+		a.traceIDs = newTraceIDWriter(settings.TraceIDOutput)
+	}
+
+	return a, nil
+}
+
+// updateReady reports ready once the ready delay has elapsed and, with
+// ReadyRequiresExport, while trace export is healthy
+func (a *App) updateReady() {
+	ready := a.warm.Load()
+	if a.settings.ReadyRequiresExport {
+		ready = ready && a.exportHealthy.Load()
+	}
+	a.health.SetReady(ready)
+}
+
+// checkTarget reports the target unreachable until a request gets a
+// response from it, and whenever the latest request got none
+func (a *App) checkTarget() error {
+	last, ok := a.recent.last()
+	if !ok {
+		return errors.New("no response from target yet")
+	}
+	if last.result.err != nil {
+		return fmt.Errorf("target unreachable: %w", last.result.err)
+	}
+	return nil
+}
+
+// Run serves health endpoints and runs the request loop until ctx is done
+func (a *App) Run(ctx context.Context) error {
+	if a.settings.CurlOutput != nil {
+		return a.runCurl(ctx)
+	}
+
+	// Hold readiness until the ready delay has elapsed
+	if a.settings.ReadyDelay > 0 {
+		a.readyTimer = time.AfterFunc(a.settings.ReadyDelay, func() {
+			a.warm.Store(true)
+			a.updateReady()
+		})
+	} else {
+		a.warm.Store(true)
+	}
+	a.updateReady()
+
+	// Start health server in background
+	go func() {
+		if err := a.health.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			a.log.Error("Health server failed", zap.Error(err))
+		}
+	}()
+
+	// Log startup information
+	a.log.Info("Starting HTTP client with OTLP tracing",
+		zap.String("target_url", a.client.ScrubURL(a.settings.URL)),
+		zap.String("probe", a.settings.Probe),
+		zap.Strings("otlp_endpoints", a.settings.OTLPEndpoints),
+		zap.String("service_name", a.settings.ServiceName),
+		zap.Duration("request_interval", a.settings.Interval),
+		zap.Duration("ramp_up", a.settings.RampUp),
+		zap.Duration("ready_delay", a.settings.ReadyDelay),
+		zap.Int64("max_bytes", a.settings.MaxBytes),
+		zap.String("schedule", a.settings.Schedule),
+		zap.String("log_level", a.settings.LogLevel),
+		zap.String("log_format", a.settings.LogFormat))
+
+	// Watch the trace export pipeline and surface outages
+	go a.tracer.WatchExportHealth(ctx, exportHealthInterval, func(healthy bool) {
+		a.health.SetExportHealthy(healthy)
+		a.exportHealthy.Store(healthy)
+		if a.settings.ReadyRequiresExport {
+			a.updateReady()
+		}
+	})
+
+	// Periodically push metrics for jobs that can't be scraped
+	go a.health.RunPush(ctx, func(err error) {
+		a.log.Warn("Failed to push metrics", zap.Error(err))
+	})
+
+	// Watch for a stalled request loop and unstick it. Gaps between
+	// scheduled runs are not stalls, and each cycle is still bounded by
+	// the request timeout.
+	if a.schedule == nil {
+		go a.watchdog.run(ctx, watchdogCheckInterval)
+	}
+
+	// Start request loop
+	a.log.Info("Starting request loop")
+
+	requestCount := 0
+	a.stats = newRunStats(a.client.Clock().Now())
+	if a.ramp != nil {
+		a.ramp.begin(a.client.Clock().Now())
+	}
+	if a.settings.BenchmarkOutput != nil {
+		a.bench = newBenchmark(a.client.Clock().Now())
+	}
+
+	var deadline <-chan time.Time
+	if a.settings.MaxRuntime > 0 {
+		timer := time.NewTimer(a.settings.MaxRuntime)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			a.finish("Shutting down")
+			return nil
+		case <-deadline:
+			a.finish("Max runtime reached, stopping",
+				zap.Duration("max_runtime", a.settings.MaxRuntime))
+			return nil
+		case <-a.ticks():
+			// Keep serving health but skip requests during maintenance. The
+			// loop is still alive, so the watchdog must not flag a stall.
+			if a.maintenance.Load() {
+				a.watchdog.touch()
+				continue
+			}
+
+			requestCount++
+			cycleCtx, done := a.watchdog.begin(ctx)
+			result := a.trackedCycle(cycleCtx, requestCount)
+			done()
+			a.record(result)
+
+			// Give up on the first failure when asked to
+			if a.settings.FailFast && !result.success {
+				a.finish("Request failed, stopping (fail-fast)",
+					zap.Int("status_code", result.statusCode),
+					zap.Error(result.err))
+				return errFailFast
+			}
+
+			// Ease the interval towards its target during a ramp-up
+			if a.ramp != nil {
+				if next := a.ramp.at(a.client.Clock().Now()); next != a.ticker.Interval() {
+					a.applyInterval(next)
+				}
+			}
+
+			// Stop once the request count or byte budget is spent
+			if a.settings.Count > 0 && requestCount >= a.settings.Count {
+				a.finish("Request count reached, stopping",
+					zap.Int("count", a.settings.Count))
+				return nil
+			}
+			if a.settings.MaxBytes > 0 && a.client.BytesRead() >= a.settings.MaxBytes {
+				a.finish("Byte limit reached, stopping",
+					zap.Int64("bytes_read", a.client.BytesRead()),
+					zap.Int64("max_bytes", a.settings.MaxBytes))
+				return nil
+			}
+		}
+	}
+}
+
+// observeExportDuration records a span export's duration on /metrics. Exports
+// only start once spans end, after NewApp has set up the health server.
+func (a *App) observeExportDuration(duration time.Duration) {
+	a.health.ObserveExportDuration(duration)
+}
+
+// errFailFast is returned by Run when FailFast stopped it after a failure
+var errFailFast = errors.New("request failed with -fail-fast set")
+
+// finish logs why the loop stopped, the run summary and the benchmark
+// report, and writes the run manifest
+func (a *App) finish(reason string, fields ...zap.Field) {
+	now := a.client.Clock().Now()
+	a.log.Info(reason, fields...)
+	a.stats.logSummary(a.log, now)
+	if a.bench != nil {
+		if err := a.bench.write(a.settings.BenchmarkOutput, now); err != nil {
+			a.log.Warn("Failed to write benchmark report", zap.Error(err))
+		}
+	}
+	if a.settings.ManifestFile != "" {
+		manifest := newRunManifest(flag.CommandLine, a.settings.BuildInfo, a.stats, now)
+		if err := writeManifest(a.settings.ManifestFile, manifest); err != nil {
+			a.log.Warn("Failed to write run manifest", zap.Error(err))
+		}
+	}
+}
+
+// ticks returns the channel that drives the request loop
+func (a *App) ticks() <-chan time.Time {
+	if a.schedule != nil {
+		return a.schedule.C()
+	}
+	return a.ticker.C()
+}
+
+// trackedCycle runs one cycle, counting it as in flight whether it was
+// started by the loop or /trigger
+func (a *App) trackedCycle(ctx context.Context, requestCount int) requestResult {
+	a.health.StartRequest()
+	defer a.health.FinishRequest()
+	return a.runCycle(ctx, requestCount)
+}
+
+// record feeds a loop cycle's outcome into stats, metrics and outputs
+func (a *App) record(result requestResult) {
+	a.stats.record(result)
+	if a.bench != nil {
+		a.bench.record(result)
+	}
+	a.recent.add(a.client.Clock().Now(), result)
+	a.health.IncrementRequests()
+	a.health.RecordOutcome(result.success)
+	a.health.SetSpansDropped(a.tracer.DroppedSpans())
+	a.health.SetRetriesExhausted(a.client.RetriesExhausted())
+	a.recordConnections()
+	if a.client.TracingEnabled() {
+		a.health.RecordSampling(result.sampled)
+	}
+
+	if a.traceIDs != nil {
+		if err := a.traceIDs.write(a.client.ScrubURL(a.settings.URL), result); err != nil {
+			a.log.Debug("Failed to write trace ID", zap.Error(err))
+		}
+	}
+
+	switch a.settings.Probe {
+	case probeHTTP:
+		a.health.ObserveDuration(result.duration)
+		if result.err == nil {
+			a.health.ObserveSizes(result.requestSize, result.responseSize)
+		}
+	case probeDNS:
+		a.health.ObserveDNSResolution(result.duration, result.err != nil)
+	}
+
+	if a.statsd != nil {
+		if err := a.statsd.RecordRequest(result.duration, !result.success); err != nil {
+			a.log.Debug("Failed to send StatsD metrics", zap.Error(err))
+		}
+	}
+
+	if a.adaptive != nil {
+		if next := a.adaptive.record(result.success); next != a.ticker.Interval() {
+			a.applyInterval(next)
+			a.log.Info("Adaptive request interval changed",
+				zap.Duration("request_interval", next),
+				zap.Bool("success", result.success))
+		}
+	}
+}
+
+// recordConnections copies the client's per-host connection pool counts to
+// the health server
+func (a *App) recordConnections() {
+	stats := a.client.ConnStats()
+	hosts := make(map[string]health.ConnCounts, len(stats))
+	for host, s := range stats {
+		hosts[host] = health.ConnCounts{Idle: s.Idle, Active: s.Active}
+	}
+	a.health.SetConnections(hosts)
+}
+
+// setMaintenance pauses or resumes the request loop for POST /maintenance
+func (a *App) setMaintenance(enabled bool) {
+	a.maintenance.Store(enabled)
+	a.log.Info("Maintenance mode changed", zap.Bool("maintenance", enabled))
+}
+
+// applyInterval retunes the ticker, stall watchdog and interval metric
+func (a *App) applyInterval(d time.Duration) {
+	a.ticker.SetInterval(d)
+	a.watchdog.setThreshold(stallThreshold(d, requestTimeout))
+	a.health.SetRequestInterval(d)
+}
+
+// DumpState writes the configuration, recent requests, metrics and
+// goroutine count to w
+func (a *App) DumpState(w io.Writer) {
+	writeStateDump(w, flag.CommandLine, a.recent, a.health)
+}
+
+// Shutdown stops the health server and flushes and closes every component.
+// Components that were never built are skipped.
+func (a *App) Shutdown(ctx context.Context) error {
+	var errs []error
+
+	if a.health != nil {
+		// Final push so short-lived runs leave their metrics behind
+		if err := a.health.Push(ctx); err != nil {
+			errs = append(errs, err)
+		}
+		if err := a.health.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop health server: %w", err))
+		}
+	}
+	if a.readyTimer != nil {
+		a.readyTimer.Stop()
+	}
+	if a.ticker != nil {
+		a.ticker.Stop()
+	}
+	if a.schedule != nil {
+		a.schedule.Stop()
+	}
+	if a.statsd != nil {
+		_ = a.statsd.Close()
+	}
+	if a.client != nil {
+		a.client.Close()
+	}
+	if a.tracer != nil {
+		if err := a.tracer.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to shutdown tracer: %w", err))
+		}
+	}
+	if a.log != nil {
+		if err := a.log.Sync(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to sync logger: %w", err))
+		}
+		if err := a.log.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close logger: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,725 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tracer-test/pkg/clock"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestApp_RunOneCycle(t *testing.T) {
+	// Create a stub target that signals the first request
+	hit := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+		select {
+		case hit <- struct{}{}:
+		default:
+		}
+	}))
+	defer server.Close()
+
+	var logs, traces syncBuffer
+	app, err := NewApp(Settings{
+		URL:           server.URL,
+		Probe:         probeHTTP,
+		Interval:      10 * time.Millisecond,
+		ServiceName:   "test-service",
+		LogLevel:      "info",
+		LogFormat:     "json",
+		LogOutput:     &logs,
+		DisableOTLP:   true,
+		HealthPort:    0,
+		TraceIDOutput: &traces,
+	})
+	if err != nil {
+		t.Fatalf("NewApp() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- app.Run(ctx)
+	}()
+
+	select {
+	case <-hit:
+	case <-time.After(5 * time.Second):
+		t.Fatal("App did not send a request")
+	}
+	cancel()
+
+	select {
+	case err := <-runErr:
+		if err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not return after cancellation")
+	}
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+	_ = app.Shutdown(shutdownCtx)
+
+	if app.stats.requests < 1 {
+		t.Errorf("Recorded %d requests, expected at least 1", app.stats.requests)
+	}
+	if !strings.Contains(logs.String(), `"msg":"run_summary"`) {
+		t.Error("Expected a run_summary log entry")
+	}
+	if !strings.HasPrefix(traces.String(), "TRACE ") {
+		t.Errorf("Trace ID output = %q, expected a TRACE line", traces.String())
+	}
+}
+
+func TestNewApp_InvalidSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings Settings
+	}{
+		{"unknown probe", Settings{Probe: "icmp", Interval: time.Second}},
+		{"injection rate above 1", Settings{Probe: probeHTTP, Interval: time.Second, FailureInjectionRate: 1.5}},
+		{"unknown TLS version", Settings{Probe: probeHTTP, Interval: time.Second, DisableOTLP: true, MinTLSVersion: "2.0"}},
+		{"invalid schedule", Settings{Probe: probeHTTP, Interval: time.Second, Schedule: "every day"}},
+		{"schedule with adaptive interval", Settings{Probe: probeHTTP, Interval: time.Second, Schedule: "@hourly", AdaptiveInterval: true}},
+		{"ramp-up with adaptive interval", Settings{Probe: probeHTTP, Interval: time.Second, RampUp: time.Minute, AdaptiveInterval: true}},
+		{"ramp-up starting below the interval", Settings{Probe: probeHTTP, Interval: time.Second, RampUp: time.Minute, RampStartInterval: time.Millisecond}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewApp(tt.settings); err == nil {
+				t.Error("NewApp() error = nil, expected an error")
+			}
+		})
+	}
+}
+
+func TestApp_AdaptiveInterval(t *testing.T) {
+	var logs syncBuffer
+	app, err := NewApp(Settings{
+		URL:              "http://example.com",
+		Probe:            probeHTTP,
+		Interval:         time.Second,
+		AdaptiveInterval: true,
+		MaxInterval:      10 * time.Second,
+		ServiceName:      "test-service",
+		LogLevel:         "info",
+		LogFormat:        "json",
+		LogOutput:        &logs,
+		DisableOTLP:      true,
+	})
+	if err != nil {
+		t.Fatalf("NewApp() error = %v", err)
+	}
+	defer func() { _ = app.Shutdown(context.Background()) }()
+	app.stats = newRunStats(time.Now())
+
+	// Consecutive failures grow the interval
+	app.record(requestResult{success: false, statusCode: 500})
+	app.record(requestResult{success: false, statusCode: 500})
+	if got := app.ticker.Interval(); got != 4*time.Second {
+		t.Errorf("Interval() after 2 failures = %s, expected %s", got, 4*time.Second)
+	}
+
+	var metrics bytes.Buffer
+	app.health.WriteMetrics(&metrics)
+	if !strings.Contains(metrics.String(), "request_interval_seconds 4\n") {
+		t.Errorf("WriteMetrics() = %q, expected request_interval_seconds 4", metrics.String())
+	}
+
+	// A success shrinks it back to the base interval
+	app.record(requestResult{success: true, statusCode: 200})
+	if got := app.ticker.Interval(); got != time.Second {
+		t.Errorf("Interval() after success = %s, expected %s", got, time.Second)
+	}
+}
+
+func TestNewApp_EnvironmentAndRegion(t *testing.T) {
+	var logs syncBuffer
+	app, err := NewApp(Settings{
+		URL:         "http://example.com",
+		Probe:       probeHTTP,
+		Interval:    time.Second,
+		ServiceName: "test-service",
+		Environment: "staging",
+		Region:      "eu-west-1",
+		LogLevel:    "info",
+		LogFormat:   "json",
+		LogOutput:   &logs,
+		DisableOTLP: true,
+	})
+	if err != nil {
+		t.Fatalf("NewApp() error = %v", err)
+	}
+	defer func() { _ = app.Shutdown(context.Background()) }()
+
+	app.log.Info("test message")
+	for _, field := range []string{`"deployment.environment":"staging"`, `"cloud.region":"eu-west-1"`} {
+		if !strings.Contains(logs.String(), field) {
+			t.Errorf("Log output %q expected to contain %s", logs.String(), field)
+		}
+	}
+}
+
+func TestApp_ReadyDelay(t *testing.T) {
+	var logs syncBuffer
+	app, err := NewApp(Settings{
+		URL:         "http://127.0.0.1:1",
+		Probe:       probeHTTP,
+		Interval:    time.Hour,
+		ServiceName: "test-service",
+		LogLevel:    "info",
+		LogFormat:   "json",
+		LogOutput:   &logs,
+		DisableOTLP: true,
+		HealthPort:  8092, // Use a specific port for testing
+		ReadyDelay:  500 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewApp() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		_ = app.Shutdown(context.Background())
+	}()
+	go func() { _ = app.Run(ctx) }()
+
+	readyStatus := func() int {
+		client := &http.Client{Timeout: time.Second}
+		resp, err := client.Get("http://" + app.health.GetAddr() + "/ready")
+		if err != nil {
+			return 0
+		}
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// Give server time to start
+	time.Sleep(100 * time.Millisecond)
+	if code := readyStatus(); code != http.StatusServiceUnavailable {
+		t.Errorf("/ready during delay = %d, expected %d", code, http.StatusServiceUnavailable)
+	}
+
+	time.Sleep(600 * time.Millisecond)
+	if code := readyStatus(); code != http.StatusOK {
+		t.Errorf("/ready after delay = %d, expected %d", code, http.StatusOK)
+	}
+}
+
+func TestApp_ReadyChecksTarget(t *testing.T) {
+	var logs syncBuffer
+	app, err := NewApp(Settings{
+		URL:               "http://127.0.0.1:1",
+		Probe:             probeHTTP,
+		Interval:          10 * time.Millisecond,
+		ServiceName:       "test-service",
+		LogLevel:          "info",
+		LogFormat:         "json",
+		LogOutput:         &logs,
+		DisableOTLP:       true,
+		HealthPort:        8094, // Use a specific port for testing
+		ReadyChecksTarget: true,
+	})
+	if err != nil {
+		t.Fatalf("NewApp() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		_ = app.Shutdown(context.Background())
+	}()
+	go func() { _ = app.Run(ctx) }()
+
+	// Give the loop time to fail against the down target
+	time.Sleep(200 * time.Millisecond)
+
+	client := &http.Client{Timeout: time.Second}
+	resp, err := client.Get("http://" + app.health.GetAddr() + "/ready")
+	if err != nil {
+		t.Fatalf("GET /ready error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Checks map[string]string `json:"checks"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode /ready body: %v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("/ready with down target = %d, expected %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+	if !strings.HasPrefix(body.Checks["target"], "target unreachable") {
+		t.Errorf("/ready target check = %q, expected it to report the target unreachable", body.Checks["target"])
+	}
+}
+
+func TestApp_RunCurl(t *testing.T) {
+	// Create a stub target with a custom header
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("X-Custom", "stub")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	var logs, out syncBuffer
+	app, err := NewApp(Settings{
+		URL:         server.URL,
+		Probe:       probeHTTP,
+		Interval:    time.Second,
+		ServiceName: "test-service",
+		LogLevel:    "info",
+		LogFormat:   "json",
+		LogOutput:   &logs,
+		DisableOTLP: true,
+		CurlOutput:  &out,
+	})
+	if err != nil {
+		t.Fatalf("NewApp() error = %v", err)
+	}
+	defer func() { _ = app.Shutdown(context.Background()) }()
+
+	// Run returns after the single request
+	if err := app.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if hits != 1 {
+		t.Errorf("Sent %d requests, expected 1", hits)
+	}
+	output := out.String()
+	if !strings.HasPrefix(output, "HTTP/1.1 202 Accepted\r\n") {
+		t.Errorf("Output = %q, expected an HTTP/1.1 202 Accepted status line", output)
+	}
+	if !strings.Contains(output, "X-Custom: stub\r\n") {
+		t.Errorf("Output = %q, expected the X-Custom header", output)
+	}
+	if !strings.HasSuffix(output, "\r\n\r\nhello") {
+		t.Errorf("Output = %q, expected the body after a blank line", output)
+	}
+}
+
+func TestApp_RunCurl_ReadBodyError(t *testing.T) {
+	// Create a stub target that closes the connection mid-body
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("short"))
+	}))
+	defer server.Close()
+
+	var logs, out syncBuffer
+	path := filepath.Join(t.TempDir(), "spans.jsonl")
+	app, err := NewApp(Settings{
+		URL:         server.URL,
+		Probe:       probeHTTP,
+		Interval:    time.Second,
+		ServiceName: "test-service",
+		LogLevel:    "info",
+		LogFormat:   "json",
+		LogOutput:   &logs,
+		TraceFile:   path,
+		CurlOutput:  &out,
+	})
+	if err != nil {
+		t.Fatalf("NewApp() error = %v", err)
+	}
+
+	if err := app.Run(context.Background()); err == nil {
+		t.Error("Run() error = nil, expected a read body error")
+	}
+	if err := app.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read export file: %v", err)
+	}
+
+	// The request.curl span carries the error
+	found := false
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var record struct {
+			Name   string `json:"Name"`
+			Status struct {
+				Code string `json:"Code"`
+			} `json:"Status"`
+			Events []struct {
+				Name string `json:"Name"`
+			} `json:"Events"`
+		}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Failed to parse span record %q: %v", line, err)
+		}
+		if record.Name != "request.curl" {
+			continue
+		}
+		found = true
+		if record.Status.Code != "Error" {
+			t.Errorf("request.curl status = %q, expected %q", record.Status.Code, "Error")
+		}
+		if len(record.Events) == 0 || record.Events[0].Name != "exception" {
+			t.Errorf("request.curl events = %v, expected an exception event", record.Events)
+		}
+	}
+	if !found {
+		t.Error("Expected a request.curl span in the export file")
+	}
+}
+
+func TestApp_MaxBytes(t *testing.T) {
+	// Create a stub target returning 100-byte bodies
+	var hits atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(bytes.Repeat([]byte("x"), 100))
+	}))
+	defer server.Close()
+
+	var logs syncBuffer
+	app, err := NewApp(Settings{
+		URL:         server.URL,
+		Probe:       probeHTTP,
+		Interval:    10 * time.Millisecond,
+		MaxBytes:    250,
+		ServiceName: "test-service",
+		LogLevel:    "info",
+		LogFormat:   "json",
+		LogOutput:   &logs,
+		DisableOTLP: true,
+	})
+	if err != nil {
+		t.Fatalf("NewApp() error = %v", err)
+	}
+	defer func() { _ = app.Shutdown(context.Background()) }()
+
+	// Run returns on its own once the cap is exceeded
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- app.Run(context.Background())
+	}()
+
+	select {
+	case err := <-runErr:
+		if err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not stop at the byte limit")
+	}
+
+	if hits.Load() != 3 {
+		t.Errorf("Sent %d requests, expected 3 to exceed 250 bytes", hits.Load())
+	}
+	if !strings.Contains(logs.String(), `"bytes_read":300`) {
+		t.Errorf("Log output %q expected bytes_read 300", logs.String())
+	}
+}
+
+func TestApp_FailFast(t *testing.T) {
+	// Create a stub target that always fails
+	var hits atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	var logs syncBuffer
+	app, err := NewApp(Settings{
+		URL:         server.URL,
+		Probe:       probeHTTP,
+		Interval:    10 * time.Millisecond,
+		FailFast:    true,
+		ServiceName: "test-service",
+		LogLevel:    "info",
+		LogFormat:   "json",
+		LogOutput:   &logs,
+		DisableOTLP: true,
+	})
+	if err != nil {
+		t.Fatalf("NewApp() error = %v", err)
+	}
+	defer func() { _ = app.Shutdown(context.Background()) }()
+
+	// Run returns on its own after the first failure
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- app.Run(context.Background())
+	}()
+
+	select {
+	case err := <-runErr:
+		if !errors.Is(err, errFailFast) {
+			t.Errorf("Run() error = %v, expected %v", err, errFailFast)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not stop after the first failure")
+	}
+
+	if hits.Load() != 1 {
+		t.Errorf("Sent %d requests, expected 1", hits.Load())
+	}
+	if !strings.Contains(logs.String(), "Request failed, stopping (fail-fast)") {
+		t.Errorf("Logs missing fail-fast stop message: %s", logs.String())
+	}
+}
+
+func TestNewApp_RampUp(t *testing.T) {
+	var logs syncBuffer
+	app, err := NewApp(Settings{
+		URL:         "http://example.com",
+		Probe:       probeHTTP,
+		Interval:    time.Second,
+		RampUp:      time.Minute,
+		ServiceName: "test-service",
+		LogLevel:    "info",
+		LogFormat:   "json",
+		LogOutput:   &logs,
+		DisableOTLP: true,
+	})
+	if err != nil {
+		t.Fatalf("NewApp() error = %v", err)
+	}
+	defer func() { _ = app.Shutdown(context.Background()) }()
+
+	// The ramp starts at ten times the target interval
+	if got := app.ticker.Interval(); got != 10*time.Second {
+		t.Errorf("Interval() = %s, expected %s", got, 10*time.Second)
+	}
+	var metrics bytes.Buffer
+	app.health.WriteMetrics(&metrics)
+	if !strings.Contains(metrics.String(), "request_rate_per_second 0.1\n") {
+		t.Errorf("WriteMetrics() = %q, expected request_rate_per_second 0.1", metrics.String())
+	}
+}
+
+func TestApp_Maintenance(t *testing.T) {
+	// Create a stub target counting requests
+	var hits atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var logs syncBuffer
+	app, err := NewApp(Settings{
+		URL:         server.URL,
+		Probe:       probeHTTP,
+		Interval:    20 * time.Millisecond,
+		ServiceName: "test-service",
+		LogLevel:    "info",
+		LogFormat:   "json",
+		LogOutput:   &logs,
+		DisableOTLP: true,
+		HealthPort:  8093, // Use a specific port for testing
+		AdminToken:  "secret",
+	})
+	if err != nil {
+		t.Fatalf("NewApp() error = %v", err)
+	}
+
+	// Drive the watchdog from a fake clock so maintenance can outlast the
+	// stall threshold
+	fakeClock := clock.NewFake(time.Now())
+	app.watchdog.clock = fakeClock
+	app.watchdog.lastActivity = fakeClock.Now()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		_ = app.Shutdown(context.Background())
+	}()
+	go func() { _ = app.Run(ctx) }()
+
+	client := &http.Client{Timeout: time.Second}
+	base := "http://" + app.health.GetAddr()
+	maintenance := func(body string) {
+		req, _ := http.NewRequest(http.MethodPost, base+"/maintenance", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("POST /maintenance error = %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("POST /maintenance %s status = %d, expected %d", body, resp.StatusCode, http.StatusOK)
+		}
+	}
+	readyStatus := func() int {
+		resp, err := client.Get(base + "/ready")
+		if err != nil {
+			return 0
+		}
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// Give server time to start
+	time.Sleep(100 * time.Millisecond)
+	maintenance("on")
+
+	// Let an in-flight request finish before counting
+	time.Sleep(50 * time.Millisecond)
+	paused := hits.Load()
+	time.Sleep(200 * time.Millisecond)
+	if got := hits.Load(); got != paused {
+		t.Errorf("Sent %d requests during maintenance, expected none", got-paused)
+	}
+	if code := readyStatus(); code != http.StatusServiceUnavailable {
+		t.Errorf("/ready during maintenance = %d, expected %d", code, http.StatusServiceUnavailable)
+	}
+
+	// A long maintenance window is not a stalled loop
+	fakeClock.Advance(2 * app.watchdog.threshold)
+	time.Sleep(100 * time.Millisecond)
+	if app.watchdog.check() {
+		t.Error("Watchdog detected a stall during maintenance")
+	}
+	var metrics bytes.Buffer
+	app.health.WriteMetrics(&metrics)
+	if !strings.Contains(metrics.String(), "loop_stalls_total 0\n") {
+		t.Errorf("WriteMetrics() = %q, expected loop_stalls_total 0", metrics.String())
+	}
+
+	maintenance("off")
+	time.Sleep(200 * time.Millisecond)
+	if hits.Load() == paused {
+		t.Error("Expected requests to resume after maintenance")
+	}
+	if code := readyStatus(); code != http.StatusOK {
+		t.Errorf("/ready after maintenance = %d, expected %d", code, http.StatusOK)
+	}
+}
+
+func TestApp_SamplingDecision(t *testing.T) {
+	// Create a stub target
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var logs syncBuffer
+	app, err := NewApp(Settings{
+		URL:          server.URL,
+		Probe:        probeHTTP,
+		Interval:     10 * time.Millisecond,
+		Count:        3,
+		ServiceName:  "test-service",
+		LogLevel:     "info",
+		LogFormat:    "json",
+		LogOutput:    &logs,
+		TraceFile:    filepath.Join(t.TempDir(), "spans.jsonl"),
+		TraceSampler: sdktrace.ParentBased(sdktrace.TraceIDRatioBased(0)),
+	})
+	if err != nil {
+		t.Fatalf("NewApp() error = %v", err)
+	}
+	defer func() { _ = app.Shutdown(context.Background()) }()
+
+	if err := app.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// Every cycle was dropped by the sampler
+	if n := strings.Count(logs.String(), `"sampled":false`); n != 3 {
+		t.Errorf("Logged sampled=false %d times, expected 3", n)
+	}
+	if strings.Contains(logs.String(), `"sampled":true`) {
+		t.Error("Expected no cycle to log sampled=true")
+	}
+	var metrics bytes.Buffer
+	app.health.WriteMetrics(&metrics)
+	for _, line := range []string{"traces_sampled_total 0\n", "traces_dropped_total 3\n"} {
+		if !strings.Contains(metrics.String(), line) {
+			t.Errorf("WriteMetrics() = %q, expected %q", metrics.String(), line)
+		}
+	}
+}
+
+func TestApp_ConnectionMetrics(t *testing.T) {
+	// Create a stub target
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var logs syncBuffer
+	app, err := NewApp(Settings{
+		URL:         server.URL,
+		Probe:       probeHTTP,
+		Interval:    10 * time.Millisecond,
+		Count:       2,
+		ServiceName: "test-service",
+		LogLevel:    "info",
+		LogFormat:   "json",
+		LogOutput:   &logs,
+		DisableOTLP: true,
+	})
+	if err != nil {
+		t.Fatalf("NewApp() error = %v", err)
+	}
+	defer func() { _ = app.Shutdown(context.Background()) }()
+
+	if err := app.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// The reused connection is back in the pool after the last cycle
+	host := strings.TrimPrefix(server.URL, "http://")
+	var metrics bytes.Buffer
+	app.health.WriteMetrics(&metrics)
+	for _, line := range []string{
+		`http_connections_idle{host="` + host + `"} 1` + "\n",
+		`http_connections_active{host="` + host + `"} 0` + "\n",
+	} {
+		if !strings.Contains(metrics.String(), line) {
+			t.Errorf("WriteMetrics() = %q, expected %q", metrics.String(), line)
+		}
+	}
+}
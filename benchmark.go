@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/influxdata/tdigest"
+)
+
+// benchmarkQuantiles are the latency percentiles in the benchmark report
+var benchmarkQuantiles = []struct {
+	name     string
+	quantile float64
+}{
+	{"p50", 0.50},
+	{"p95", 0.95},
+	{"p99", 0.99},
+}
+
+// benchmark collects per-request latencies and volumes for the end-of-run
+// benchmark report
+type benchmark struct {
+	started  time.Time
+	requests int
+	failures int
+	bytes    int64
+
+	latencies *tdigest.TDigest
+	total     time.Duration
+	min       time.Duration
+	max       time.Duration
+}
+
+// newBenchmark creates an empty benchmark starting at started
+func newBenchmark(started time.Time) *benchmark {
+	return &benchmark{
+		started:   started,
+		latencies: tdigest.NewWithCompression(100),
+	}
+}
+
+// record adds a cycle's latency and outcome
+func (b *benchmark) record(result requestResult) {
+	b.requests++
+	if !result.success {
+		b.failures++
+	}
+	b.bytes += int64(result.requestSize + result.responseSize)
+
+	b.latencies.Add(float64(result.duration), 1)
+	b.total += result.duration
+	if b.requests == 1 || result.duration < b.min {
+		b.min = result.duration
+	}
+	if result.duration > b.max {
+		b.max = result.duration
+	}
+}
+
+// write prints the report as a table of metrics
+func (b *benchmark) write(w io.Writer, now time.Time) error {
+	elapsed := now.Sub(b.started)
+	seconds := elapsed.Seconds()
+
+	var rate, errorRate, bytesRate float64
+	var avg time.Duration
+	if seconds > 0 {
+		rate = float64(b.requests) / seconds
+		bytesRate = float64(b.bytes) / seconds
+	}
+	if b.requests > 0 {
+		errorRate = float64(b.failures) / float64(b.requests) * 100
+		avg = b.total / time.Duration(b.requests)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METRIC\tVALUE")
+	fmt.Fprintf(tw, "requests\t%d\n", b.requests)
+	fmt.Fprintf(tw, "duration\t%s\n", elapsed.Round(time.Millisecond))
+	fmt.Fprintf(tw, "requests/sec\t%.2f\n", rate)
+	fmt.Fprintf(tw, "error rate\t%.2f%%\n", errorRate)
+	fmt.Fprintf(tw, "bytes/sec\t%.2f\n", bytesRate)
+	fmt.Fprintf(tw, "latency min\t%s\n", b.min.Round(time.Microsecond))
+	fmt.Fprintf(tw, "latency avg\t%s\n", avg.Round(time.Microsecond))
+	for _, bq := range benchmarkQuantiles {
+		fmt.Fprintf(tw, "latency %s\t%s\n", bq.name, b.quantile(bq.quantile).Round(time.Microsecond))
+	}
+	fmt.Fprintf(tw, "latency max\t%s\n", b.max.Round(time.Microsecond))
+	return tw.Flush()
+}
+
+// quantile returns the estimated latency at q (0-1), or 0 with no requests
+func (b *benchmark) quantile(q float64) time.Duration {
+	if b.requests == 0 {
+		return 0
+	}
+	return time.Duration(b.latencies.Quantile(q))
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApp_Benchmark(t *testing.T) {
+	// Create a stub target
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	var logs, report syncBuffer
+	app, err := NewApp(Settings{
+		URL:             server.URL,
+		Probe:           probeHTTP,
+		Interval:        time.Millisecond,
+		Count:           5,
+		BenchmarkOutput: &report,
+		ServiceName:     "test-service",
+		LogLevel:        "info",
+		LogFormat:       "json",
+		LogOutput:       &logs,
+		DisableOTLP:     true,
+	})
+	if err != nil {
+		t.Fatalf("NewApp() error = %v", err)
+	}
+	defer func() { _ = app.Shutdown(context.Background()) }()
+
+	// Run returns on its own after -count requests
+	if err := app.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	output := report.String()
+	for _, row := range []string{
+		`requests +5`,
+		`requests/sec +[0-9.]+`,
+		`error rate +0\.00%`,
+		`bytes/sec +[0-9.]+`,
+		`latency min +\S+`,
+		`latency avg +\S+`,
+		`latency p50 +\S+`,
+		`latency p95 +\S+`,
+		`latency p99 +\S+`,
+		`latency max +\S+`,
+	} {
+		if !regexp.MustCompile(`(?m)^` + row + `$`).MatchString(output) {
+			t.Errorf("Report %q expected a row matching %q", output, row)
+		}
+	}
+	if !strings.Contains(logs.String(), `"msg":"Request count reached, stopping"`) {
+		t.Error("Expected a log entry for reaching the request count")
+	}
+}
+
+func TestApp_MaxRuntime(t *testing.T) {
+	var logs syncBuffer
+	app, err := NewApp(Settings{
+		URL:         "http://127.0.0.1:1",
+		Probe:       probeHTTP,
+		Interval:    time.Hour,
+		MaxRuntime:  100 * time.Millisecond,
+		ServiceName: "test-service",
+		LogLevel:    "info",
+		LogFormat:   "json",
+		LogOutput:   &logs,
+		DisableOTLP: true,
+	})
+	if err != nil {
+		t.Fatalf("NewApp() error = %v", err)
+	}
+	defer func() { _ = app.Shutdown(context.Background()) }()
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- app.Run(context.Background())
+	}()
+
+	select {
+	case err := <-runErr:
+		if err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not stop at the max runtime")
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckCerts(t *testing.T) {
+	// Create a TLS test server with a self-signed certificate
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	cert := server.Certificate()
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		expected int
+		output   string
+	}{
+		{"valid", cert.NotBefore.Add(time.Hour), 0, "subject: O=Acme Co"},
+		{"expired", cert.NotAfter.Add(time.Hour), 1, "expired at"},
+		{"not yet valid", cert.NotBefore.Add(-time.Hour), 1, "is not valid until"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if code := checkCerts(&out, []string{server.URL}, tt.now); code != tt.expected {
+				t.Errorf("checkCerts() = %d, expected %d", code, tt.expected)
+			}
+			if !strings.Contains(out.String(), tt.output) {
+				t.Errorf("checkCerts() output = %q, expected to contain %q", out.String(), tt.output)
+			}
+		})
+	}
+}
+
+func TestCheckCerts_NotHTTPS(t *testing.T) {
+	var out bytes.Buffer
+	if code := checkCerts(&out, []string{"http://localhost:4318"}, time.Now()); code != 1 {
+		t.Errorf("checkCerts() = %d, expected 1", code)
+	}
+	if !strings.Contains(out.String(), "not an https endpoint") {
+		t.Errorf("checkCerts() output = %q, expected an https error", out.String())
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// runCurl makes a single traced GET request to the target URL and writes
+// the response to CurlOutput like curl -i. Non-2xx responses are printed,
+// not returned as errors; only transport failures are.
+func (a *App) runCurl(ctx context.Context) error {
+	ctx, span := a.tracer.GetTracer().Start(ctx, "request.curl",
+		trace.WithAttributes(attribute.String("request.target_url", a.client.ScrubURL(a.settings.URL))))
+	defer span.End()
+
+	traceCtx := a.log.WithTraceContext(span.SpanContext().TraceID().String(), span.SpanContext().SpanID().String())
+
+	resp, err := a.client.Get(ctx, a.settings.URL)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("request failed: %w", err)
+	}
+	body, err := a.client.ReadBody(ctx, resp)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+	if a.client.IsErrorStatus(resp.StatusCode) {
+		span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", resp.StatusCode))
+	} else {
+		span.SetStatus(codes.Ok, "")
+	}
+
+	traceCtx.Info("Curl request completed",
+		zap.String("url", a.client.ScrubURL(a.settings.URL)),
+		zap.Int("status_code", resp.StatusCode))
+
+	return writeCurlResponse(a.settings.CurlOutput, resp, body)
+}
+
+// writeCurlResponse writes the status line, headers and body of resp
+func writeCurlResponse(w io.Writer, resp *http.Response, body []byte) error {
+	if _, err := fmt.Fprintf(w, "%s %s\r\n", resp.Proto, resp.Status); err != nil {
+		return err
+	}
+	if err := resp.Header.Write(w); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, "\r\n"); err != nil {
+		return err
+	}
+	_, err := w.Write(body)
+	return err
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+	"sort"
+
+	"tracer-test/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// deprecatedFlags maps legacy flag names to the flag or setting that
+// replaces them. Deprecated flags keep working but warn when set. Nothing
+// is deprecated yet; a flag stays registered when it is added here.
+var deprecatedFlags = map[string]string{}
+
+// warnDeprecatedFlags logs one "deprecated_flag" warning for each deprecated
+// flag that was explicitly set on fs
+func warnDeprecatedFlags(fs *flag.FlagSet, deprecated map[string]string, log *logger.Logger) {
+	var set []string
+	fs.Visit(func(f *flag.Flag) {
+		if _, ok := deprecated[f.Name]; ok {
+			set = append(set, f.Name)
+		}
+	})
+	sort.Strings(set)
+
+	for _, name := range set {
+		log.Warn("deprecated_flag",
+			zap.String("flag", name),
+			zap.String("replacement", deprecated[name]))
+	}
+}
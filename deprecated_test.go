@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"tracer-test/pkg/logger"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestWarnDeprecatedFlags(t *testing.T) {
+	deprecated := map[string]string{"target-url": "-url"}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected int
+	}{
+		{"deprecated flag set", []string{"-target-url", "http://example.com"}, 1},
+		{"deprecated flag unset", []string{"-url", "http://example.com"}, 0},
+		{"no flags", nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.String("target-url", "", "")
+			fs.String("url", "https://httpbin.org/get", "")
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			// Create a test logger with observer
+			core, recorded := observer.New(zapcore.WarnLevel)
+			log := &logger.Logger{Logger: zap.New(core)}
+
+			warnDeprecatedFlags(fs, deprecated, log)
+
+			warnings := recorded.FilterMessage("deprecated_flag")
+			if warnings.Len() != tt.expected {
+				t.Fatalf("deprecated_flag warnings = %d, expected %d", warnings.Len(), tt.expected)
+			}
+			if tt.expected == 0 {
+				return
+			}
+			fields := warnings.All()[0].ContextMap()
+			if fields["flag"] != "target-url" || fields["replacement"] != "-url" {
+				t.Errorf("deprecated_flag fields = %v, expected flag=target-url replacement=-url", fields)
+			}
+		})
+	}
+}
+
+func TestDeprecatedFlags_Registered(t *testing.T) {
+	// Every deprecated flag must still be accepted on the command line
+	for name := range deprecatedFlags {
+		if flag.Lookup(name) == nil {
+			t.Errorf("Deprecated flag -%s is not registered", name)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"tracer-test/pkg/health"
+)
+
+// recentRequestsSize is how many request outcomes are kept for state dumps
+const recentRequestsSize = 20
+
+// recentRequest is a request outcome kept for state dumps
+type recentRequest struct {
+	at     time.Time
+	result requestResult
+}
+
+// recentRequests keeps the last few request outcomes
+type recentRequests struct {
+	mu      sync.Mutex
+	entries []recentRequest
+}
+
+// add records a request outcome, dropping the oldest when full
+func (r *recentRequests) add(at time.Time, result requestResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = append(r.entries, recentRequest{at: at, result: result})
+	if len(r.entries) > recentRequestsSize {
+		r.entries = r.entries[len(r.entries)-recentRequestsSize:]
+	}
+}
+
+// snapshot returns a copy of the recorded outcomes, oldest first
+func (r *recentRequests) snapshot() []recentRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]recentRequest(nil), r.entries...)
+}
+
+// last returns the most recent outcome, if any
+func (r *recentRequests) last() (recentRequest, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.entries) == 0 {
+		return recentRequest{}, false
+	}
+	return r.entries[len(r.entries)-1], true
+}
+
+// redactedFlagValue returns the flag's value, redacting flags whose name
+// mentions a token
+func redactedFlagValue(f *flag.Flag) string {
+	value := f.Value.String()
+	if strings.Contains(f.Name, "token") && value != "" {
+		return "[redacted]"
+	}
+	return value
+}
+
+// writeStateDump writes the current configuration, recent requests, metrics
+// and goroutine count to w. Flags whose name mentions a token are redacted.
+func writeStateDump(w io.Writer, flags *flag.FlagSet, recent *recentRequests, healthServer *health.Server) {
+	_, _ = fmt.Fprintf(w, "=== tracer-test state dump (version %s) ===\n", version)
+
+	_, _ = fmt.Fprintln(w, "\n--- config ---")
+	flags.VisitAll(func(f *flag.Flag) {
+		_, _ = fmt.Fprintf(w, "%s=%s\n", f.Name, redactedFlagValue(f))
+	})
+
+	_, _ = fmt.Fprintln(w, "\n--- recent requests ---")
+	for _, entry := range recent.snapshot() {
+		errText := ""
+		if entry.result.err != nil {
+			errText = entry.result.err.Error()
+		}
+		_, _ = fmt.Fprintf(w, "%s success=%t status=%d duration=%s trace_id=%s error=%q\n",
+			entry.at.Format(time.RFC3339), entry.result.success, entry.result.statusCode,
+			entry.result.duration, entry.result.traceID, errText)
+	}
+
+	_, _ = fmt.Fprintln(w, "\n--- metrics ---")
+	healthServer.WriteMetrics(w)
+
+	_, _ = fmt.Fprintln(w, "\n--- runtime ---")
+	_, _ = fmt.Fprintf(w, "goroutines=%d\n", runtime.NumGoroutine())
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+	"time"
+
+	"tracer-test/pkg/health"
+)
+
+func TestWriteStateDump(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.String("url", "https://example.com", "")
+	flags.String("admin-token", "secret", "")
+
+	recent := &recentRequests{}
+	at := time.Date(2024, 1, 15, 10, 30, 45, 0, time.UTC)
+	recent.add(at, requestResult{success: true, statusCode: 200, duration: 42 * time.Millisecond, traceID: "4bf92f3577b34da6a3ce929d0e0e4736"})
+	recent.add(at, requestResult{err: errors.New("connection refused")})
+
+	healthServer := health.New(8080)
+	healthServer.IncrementRequests()
+
+	var out bytes.Buffer
+	writeStateDump(&out, flags, recent, healthServer)
+	dump := out.String()
+
+	expected := []string{
+		"--- config ---",
+		"url=https://example.com",
+		"admin-token=[redacted]",
+		"--- recent requests ---",
+		"trace_id=4bf92f3577b34da6a3ce929d0e0e4736",
+		`error="connection refused"`,
+		"--- metrics ---",
+		"http_requests_total 1",
+		"--- runtime ---",
+		"goroutines=",
+	}
+	for _, want := range expected {
+		if !strings.Contains(dump, want) {
+			t.Errorf("writeStateDump() output missing %q", want)
+		}
+	}
+	if strings.Contains(dump, "secret") {
+		t.Error("writeStateDump() output contains the admin token")
+	}
+}
+
+func TestRecentRequests_KeepsLatest(t *testing.T) {
+	recent := &recentRequests{}
+	for i := 0; i < recentRequestsSize+5; i++ {
+		recent.add(time.Time{}, requestResult{statusCode: i})
+	}
+
+	entries := recent.snapshot()
+	if len(entries) != recentRequestsSize {
+		t.Fatalf("snapshot() length = %d, expected %d", len(entries), recentRequestsSize)
+	}
+	if entries[0].result.statusCode != 5 {
+		t.Errorf("Oldest entry status = %d, expected 5", entries[0].result.statusCode)
+	}
+}
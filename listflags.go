@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+)
+
+// flagEntry describes one command-line flag for -list-flags
+type flagEntry struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Default string `json:"default"`
+	Value   string `json:"value"`
+	Usage   string `json:"usage"`
+}
+
+// boolFlag is implemented by flag values that take no argument
+type boolFlag interface {
+	IsBoolFlag() bool
+}
+
+// flagType returns the type name shown for f, e.g. string, int or duration
+func flagType(f *flag.Flag) string {
+	if b, ok := f.Value.(boolFlag); ok && b.IsBoolFlag() {
+		return "bool"
+	}
+	name, _ := flag.UnquoteUsage(f)
+	return name
+}
+
+// writeFlagList writes every flag's name, type, default and current value
+// to w as a JSON array sorted by name, with tokens redacted
+func writeFlagList(w io.Writer, flags *flag.FlagSet) error {
+	entries := []flagEntry{}
+	flags.VisitAll(func(f *flag.Flag) {
+		entries = append(entries, flagEntry{
+			Name:    f.Name,
+			Type:    flagType(f),
+			Default: f.DefValue,
+			Value:   redactedFlagValue(f),
+			Usage:   f.Usage,
+		})
+	})
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(entries)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"testing"
+	"time"
+)
+
+func TestWriteFlagList(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.String("url", "https://httpbin.org/get", "URL to make GET request to")
+	flags.Duration("interval", 5*time.Second, "Interval between requests")
+	flags.Bool("disable-otlp", false, "Disable OTLP tracing export")
+	flags.String("admin-token", "", "Bearer token for administrative endpoints")
+	if err := flags.Parse([]string{"-interval", "1s", "-admin-token", "secret"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := writeFlagList(&out, flags); err != nil {
+		t.Fatalf("writeFlagList() error = %v", err)
+	}
+
+	var entries []flagEntry
+	if err := json.Unmarshal(out.Bytes(), &entries); err != nil {
+		t.Fatalf("writeFlagList() output is not valid JSON: %v\n%s", err, out.String())
+	}
+	byName := make(map[string]flagEntry, len(entries))
+	for _, entry := range entries {
+		byName[entry.Name] = entry
+	}
+
+	expected := map[string]flagEntry{
+		"url":          {Name: "url", Type: "string", Default: "https://httpbin.org/get", Value: "https://httpbin.org/get", Usage: "URL to make GET request to"},
+		"interval":     {Name: "interval", Type: "duration", Default: "5s", Value: "1s", Usage: "Interval between requests"},
+		"disable-otlp": {Name: "disable-otlp", Type: "bool", Default: "false", Value: "false", Usage: "Disable OTLP tracing export"},
+		"admin-token":  {Name: "admin-token", Type: "string", Default: "", Value: "[redacted]", Usage: "Bearer token for administrative endpoints"},
+	}
+	if len(entries) != len(expected) {
+		t.Errorf("writeFlagList() listed %d flags, expected %d", len(entries), len(expected))
+	}
+	for name, want := range expected {
+		if got := byName[name]; got != want {
+			t.Errorf("writeFlagList() %s = %+v, expected %+v", name, got, want)
+		}
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"io"
 	"os"
 	"os/signal"
+	"regexp"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -14,10 +16,11 @@ import (
 	"tracer-test/pkg/help"
 	"tracer-test/pkg/httpclient"
 	"tracer-test/pkg/logger"
-	"tracer-test/pkg/tracer"
 
+	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
@@ -28,17 +31,94 @@ var (
 	commit  = "unknown"
 	date    = "unknown"
 
-	targetURL     = flag.String("url", "https://httpbin.org/get", "URL to make GET request to")
-	otlpEndpoint  = flag.String("otlp-endpoint", "http://localhost:4318", "OTLP endpoint for traces")
-	serviceName   = flag.String("service-name", "http-client", "Service name for tracing")
-	interval      = flag.Duration("interval", 5*time.Second, "Interval between requests")
-	logLevel      = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
-	logFormat     = flag.String("log-format", "json", "Log format (json, console)")
-	disableOTLP   = flag.Bool("disable-otlp", false, "Disable OTLP tracing export")
-	showHelp      = flag.Bool("help", false, "Show help message")
-	showVersion   = flag.Bool("version", false, "Show version information")
+	targetURL        = flag.String("url", "https://httpbin.org/get", "URL to make GET request to")
+	urlStdin         = flag.Bool("url-stdin", false, "Read the target URL from stdin instead of -url")
+	otlpEndpoint     = flag.String("otlp-endpoint", "http://localhost:4318", "OTLP endpoint for traces (comma-separated to export to several)")
+	otlpCACert       = flag.String("otlp-ca-cert", "", "PEM file of CA certificates to trust for https OTLP endpoints (default: system roots)")
+	serviceName      = flag.String("service-name", "http-client", "Service name for tracing")
+	environment      = flag.String("environment", os.Getenv("DEPLOYMENT_ENVIRONMENT"), "Deployment environment recorded on spans and logs (default: $DEPLOYMENT_ENVIRONMENT)")
+	region           = flag.String("region", os.Getenv("CLOUD_REGION"), "Cloud region recorded on spans and logs (default: $CLOUD_REGION)")
+	synthetic        = flag.Bool("synthetic", false, "Flag root spans and the resource as synthetic monitoring traffic")
+	includeVCSInfo   = flag.Bool("include-vcs-info", false, "Record the VCS revision, commit time and modified flag from the build on the trace resource")
+	propagators      = flag.String("propagators", "tracecontext,baggage", "Comma-separated trace context formats sent to the target (tracecontext, baggage, b3, b3multi, jaeger)")
+	interval         = flag.Duration("interval", 5*time.Second, "Interval between requests")
+	schedule         = flag.String("schedule", "", "Cron expression to run requests on instead of -interval (e.g. \"*/5 9-17 * * 1-5\")")
+	logLevel         = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
+	logFormat        = flag.String("log-format", "json", "Log format (json, console, logfmt)")
+	logFieldNames    = flag.String("log-field-names", "", "Comma-separated default=custom pairs renaming the timestamp, level, msg and caller log keys (e.g. level=severity)")
+	logBufferSize    = flag.Int("log-buffer-size", 0, "Buffer up to this many bytes of log output before writing it (default: unbuffered)")
+	logFlushInterval = flag.Duration("log-flush-interval", 0, "With -log-buffer-size, flush buffered logs at least this often (default: 30s)")
+	traceURLTemplate = flag.String("trace-url-template", "", "Template of a link to each cycle's trace, logged as trace_url, e.g. https://jaeger.example.com/trace/{{.TraceID}}")
+	disableOTLP      = flag.Bool("disable-otlp", false, "Disable OTLP tracing export")
+	traceFile        = flag.String("trace-file", "", "Write spans as JSON lines to this file instead of exporting via OTLP")
+	exportErrorsOnly = flag.Bool("export-on-error-only", false, "Only export spans of request cycles that failed or were slow")
+	slowThreshold    = flag.Duration("slow-cycle-threshold", 0, "With -export-on-error-only, also export cycles at least this slow")
+	minSpanDuration  = flag.Duration("min-span-duration", 0, "Only export cycles slower than this, dropping the spans of faster ones")
+	otlpQueueSize    = flag.Int("otlp-queue-size", 2048, "Spans queued per exporter before new spans are dropped")
+	otlpBlockOnFull  = flag.Bool("otlp-block-on-queue-full", false, "Wait for room in a full export queue instead of dropping spans, adding latency to requests")
+	exportDuration   = flag.Bool("otlp-export-duration", false, "Expose the time spent exporting spans as the otlp_export_duration_seconds histogram on /metrics")
+	sampleRatio      = flag.Float64("sample-ratio", 1, "Fraction (0-1) of request cycles to trace")
+	detailRatio      = flag.Float64("detail-span-sample-ratio", 1, "Fraction (0-1) of requests that get transport, DNS and TCP spans")
+	maxSpansPerCycle = flag.Int("max-spans-per-cycle", 0, "Stop exporting child spans once a cycle has this many (default: unlimited)")
+	accept           = flag.String("accept", "", "Accept header to send with each request (default: let the server choose)")
+	nonErrorCodes    = flag.String("non-error-status-codes", "", "Comma-separated status codes >= 400 that are not treated as errors (e.g. 404,410)")
+	expectedCodes    = flag.String("expected-status-codes", "", "Comma-separated status codes treated as success; any other code fails the request (default: any code < 400)")
+	probeMode        = flag.String("probe", probeHTTP, "Probe mode (http, tcp, dns)")
+	statsdAddr       = flag.String("statsd-addr", "", "StatsD host:port to send request metrics to over UDP (default: disabled)")
+	enableTrigger    = flag.Bool("enable-trigger", false, "Expose POST /trigger on the health server to fire a request on demand")
+	adminToken       = flag.String("admin-token", "", "Bearer token required by administrative health endpoints")
+	healthMethods    = flag.String("health-methods", "GET,HEAD", "Comma-separated HTTP methods accepted by /health, /ready, /metrics and /info")
+	readyNeedsExport = flag.Bool("ready-requires-export", false, "Report not-ready while trace export is failing")
+	readyNeedsTarget = flag.Bool("ready-checks-target", false, "Report not-ready until the target responds and while its latest request got no response")
+	readyWindow      = flag.Int("ready-window", 0, "Derive readiness from the last N request outcomes (default: disabled)")
+	readyMaxFailures = flag.Float64("ready-failure-threshold", 0.5, "With -ready-window, report not-ready when the recent failure rate exceeds this fraction")
+	readyDelay       = flag.Duration("ready-delay", 0, "Report not-ready for this long after startup while dependencies warm up")
+	captureHeaders   = flag.String("capture-response-headers", "", "Comma-separated response headers to record on spans and carry as baggage on later requests")
+	dnsCacheTTL      = flag.Duration("dns-cache-ttl", 0, "Cache successful DNS resolutions in process for this long (default: disabled)")
+	maxConcurrentDNS = flag.Int("max-concurrent-dns", 0, "Maximum DNS lookups in flight at once (default: unlimited)")
+	dialTimeout      = flag.Duration("dial-timeout", 30*time.Second, "Time allowed to establish each TCP connection")
+	keepAlive        = flag.Duration("tcp-keep-alive", 30*time.Second, "TCP keep-alive period for connections (negative disables)")
+	sockReadTimeout  = flag.Duration("socket-read-timeout", 0, "Time allowed for each socket read before the connection is considered hung (0 disables)")
+	sockWriteTimeout = flag.Duration("socket-write-timeout", 0, "Time allowed for each socket write before the connection is considered hung (0 disables)")
+	maxRetries       = flag.Int("max-retries", 0, "Retry requests failing with a network error or 5xx status up to this many times (default: no retries)")
+	retryBackoff     = flag.Duration("retry-backoff", 100*time.Millisecond, "With -max-retries, the backoff before the first retry, doubled per retry with jitter")
+	scrubParams      = flag.String("scrub-query-params", "", "Comma-separated query parameters redacted from traced and logged URLs (\"*\" drops the whole query)")
+	minTLSVersion    = flag.String("min-tls-version", "", "Minimum TLS version (1.0, 1.1, 1.2, 1.3)")
+	cipherSuites     = flag.String("cipher-suites", "", "Comma-separated allowed TLS 1.0-1.2 cipher suites (Go names)")
+	peerServices     = flag.String("peer-services", "", "Comma-separated service=url pairs naming the service behind each target URL (recorded as peer.service)")
+	pushgatewayURL   = flag.String("pushgateway-url", "", "Prometheus Pushgateway URL to push metrics to on shutdown (default: disabled)")
+	pushInterval     = flag.Duration("push-interval", 0, "With -pushgateway-url, also push metrics this often (default: only on shutdown)")
+	pushJob          = flag.String("push-job", "", "With -pushgateway-url, the job label (default: the service name)")
+	pushInstance     = flag.String("push-instance", "", "With -pushgateway-url, the instance label (default: none)")
+	metricsLabels    = flag.String("metrics-labels", "", "Comma-separated name=value constant labels added to every metric (e.g. service=api,environment=prod)")
+	bearerTokenFile  = flag.String("bearer-token-file", "", "File containing a bearer token sent with each request, reread periodically to pick up rotation")
+	injectionRate    = flag.Float64("failure-injection-rate", 0, "Probability (0-1) of failing a request with a synthetic error instead of sending it")
+	injectedLatency  = flag.Duration("injected-latency", 0, "Delay every request by this long before sending it (default: disabled)")
+	latencyJitter    = flag.Duration("injected-latency-jitter", 0, "Add a random delay up to this long to every request (default: disabled)")
+	curlMode         = flag.Bool("curl", false, "Make a single traced request to -url, print the response like curl -i and exit")
+	checkCertMode    = flag.Bool("check-cert", false, "Print the TLS certificate chain of the OTLP endpoints (or -url with -disable-otlp) and exit, non-zero if a certificate is expired or not yet valid")
+	emitTraceIDs     = flag.Bool("emit-trace-ids", false, "Print \"TRACE <trace_id> <url> <status>\" to stdout after each request cycle")
+	adaptiveMode     = flag.Bool("adaptive-interval", false, "Back the interval off exponentially while requests keep failing, resetting on the first success")
+	maxInterval      = flag.Duration("max-interval", 5*time.Minute, "With -adaptive-interval, the longest the interval may back off to")
+	rampDuration     = flag.Duration("ramp-up", 0, "Shorten the interval linearly from -ramp-start-interval to -interval over this long (default: disabled)")
+	rampStart        = flag.Duration("ramp-start-interval", 0, "With -ramp-up, the interval to start from (default: 10x -interval)")
+	count            = flag.Int("count", 0, "Stop after this many requests (default: unlimited)")
+	maxRuntime       = flag.Duration("max-runtime", 0, "Stop after running this long (default: unlimited)")
+	failFast         = flag.Bool("fail-fast", false, "Stop and exit non-zero on the first failed request or unexpected status")
+	benchmarkMode    = flag.Bool("benchmark", false, "Print a latency and throughput report to stdout when the run ends")
+	manifestFile     = flag.String("manifest-file", "", "Write a JSON manifest of the configuration, build and run stats to this file when the run ends")
+	maxBytes         = flag.Int64("max-bytes", 0, "Stop once this many response body bytes have been downloaded (default: unlimited)")
+	showHelp         = flag.Bool("help", false, "Show help message")
+	showVersion      = flag.Bool("version", false, "Show version information")
+	listFlags        = flag.Bool("list-flags", false, "Print every flag's name, type, default and current value as JSON and exit")
 )
 
+// requestTimeout bounds each HTTP request and TCP probe
+const requestTimeout = 10 * time.Second
+
+// exportHealthInterval is how often the trace export pipeline is checked
+const exportHealthInterval = 10 * time.Second
+
 func main() {
 	flag.Parse()
 
@@ -56,63 +136,48 @@ func main() {
 		os.Exit(0)
 	}
 
-	// Initialize logger
-	log, err := logger.New(logger.Config{
-		Level:  *logLevel,
-		Format: *logFormat,
-	})
+	// List flags for wrapping tools
+	if *listFlags {
+		if err := writeFlagList(os.Stdout, flag.CommandLine); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to list flags: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	settings, err := settingsFromFlags()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Invalid flags: %v\n", err)
 		os.Exit(1)
 	}
-	defer func() {
-		if err := log.Sync(); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to sync logger: %v\n", err)
-		}
-	}()
 
-	// Initialize tracer
-	t, err := tracer.New(tracer.Config{
-		Endpoint:    *otlpEndpoint,
-		ServiceName: *serviceName,
-		Disabled:    *disableOTLP,
-	}, log.Logger)
+	// Inspect TLS certificates instead of running
+	if *checkCertMode {
+		os.Exit(checkCerts(os.Stdout, certTargets(settings), time.Now()))
+	}
+
+	app, err := NewApp(settings)
 	if err != nil {
-		log.Error("Failed to initialize tracer", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Failed to start: %v\n", err)
 		os.Exit(1)
 	}
+	warnDeprecatedFlags(flag.CommandLine, deprecatedFlags, app.log)
+
+	// Exit non-zero only after the shutdown below has flushed spans
+	exitCode := 0
 	defer func() {
-		if err := t.Shutdown(context.Background()); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to shutdown tracer: %v\n", err)
+		if exitCode != 0 {
+			os.Exit(exitCode)
 		}
 	}()
-
-	// Initialize HTTP client
-	client := httpclient.New(httpclient.Config{
-		Timeout: 10 * time.Second,
-	}, log.Logger, t.GetTracer())
-	defer client.Close()
-
-	// Initialize health server
-	healthServer := health.New(8080)
-	healthServer.SetReady(true)
-
-	// Start health server in background
-	go func() {
-		if err := healthServer.Start(); err != nil {
-			log.Error("Health server failed", zap.Error(err))
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := app.Shutdown(shutdownCtx); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to shut down cleanly: %v\n", err)
 		}
 	}()
 
-	// Log startup information
-	log.Info("Starting HTTP client with OTLP tracing",
-		zap.String("target_url", *targetURL),
-		zap.String("otlp_endpoint", *otlpEndpoint),
-		zap.String("service_name", *serviceName),
-		zap.Duration("request_interval", *interval),
-		zap.String("log_level", *logLevel),
-		zap.String("log_format", *logFormat))
-
 	// Set up signal handling for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -122,113 +187,406 @@ func main() {
 
 	go func() {
 		<-sigChan
-		log.Info("Received shutdown signal")
+		app.log.Info("Received shutdown signal")
 		cancel()
 	}()
 
-	// Start request loop
-	log.Info("Starting request loop")
-	
-	requestCount := 0
-	ticker := time.NewTicker(*interval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			log.Info("Shutting down")
-			// Gracefully shutdown health server
-			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-			if err := healthServer.Stop(shutdownCtx); err != nil {
-				log.Error("Failed to stop health server", zap.Error(err))
-			}
-			return
-		case <-ticker.C:
-			requestCount++
-			makeRequest(ctx, client, log, t.GetTracer(), *targetURL, requestCount)
-			healthServer.IncrementRequests()
+	// Dump state to stderr on SIGQUIT without exiting
+	quitChan := make(chan os.Signal, 1)
+	signal.Notify(quitChan, syscall.SIGQUIT)
+
+	go func() {
+		for range quitChan {
+			app.log.Info("Received SIGQUIT, dumping state to stderr")
+			app.DumpState(os.Stderr)
+		}
+	}()
+
+	if err := app.Run(ctx); err != nil {
+		app.log.Error("Request loop failed", zap.Error(err))
+		exitCode = 1
+	}
+}
+
+// settingsFromFlags builds App settings from the parsed command line flags
+func settingsFromFlags() (Settings, error) {
+	nonErrorStatusCodes, err := parseStatusCodes(*nonErrorCodes)
+	if err != nil {
+		return Settings{}, fmt.Errorf("-non-error-status-codes: %w", err)
+	}
+
+	expectedStatusCodes, err := parseStatusCodes(*expectedCodes)
+	if err != nil {
+		return Settings{}, fmt.Errorf("-expected-status-codes: %w", err)
+	}
+
+	peerServiceNames, err := parsePeerServices(*peerServices)
+	if err != nil {
+		return Settings{}, fmt.Errorf("-peer-services: %w", err)
+	}
+
+	logKeys, err := parseFieldNames(*logFieldNames)
+	if err != nil {
+		return Settings{}, fmt.Errorf("-log-field-names: %w", err)
+	}
+
+	constLabels, err := parseLabels(*metricsLabels)
+	if err != nil {
+		return Settings{}, fmt.Errorf("-metrics-labels: %w", err)
+	}
+
+	if *schedule != "" && flagWasSet(flag.CommandLine, "interval") {
+		return Settings{}, fmt.Errorf("-schedule and -interval are mutually exclusive")
+	}
+
+	target := *targetURL
+	if *urlStdin {
+		if flagWasSet(flag.CommandLine, "url") {
+			return Settings{}, fmt.Errorf("-url-stdin and -url are mutually exclusive")
+		}
+		if target, err = targetFromStdin(os.Stdin); err != nil {
+			return Settings{}, fmt.Errorf("-url-stdin: %w", err)
+		}
+	}
+
+	if *sampleRatio < 0 || *sampleRatio > 1 {
+		return Settings{}, fmt.Errorf("-sample-ratio: %g is not between 0 and 1", *sampleRatio)
+	}
+	if *detailRatio < 0 || *detailRatio > 1 {
+		return Settings{}, fmt.Errorf("-detail-span-sample-ratio: %g is not between 0 and 1", *detailRatio)
+	}
+
+	settings := Settings{
+		URL:         target,
+		Probe:       *probeMode,
+		Interval:    *interval,
+		Schedule:    *schedule,
+		ServiceName: *serviceName,
+		Environment: *environment,
+		Region:      *region,
+		Synthetic:   *synthetic,
+		Propagators: *propagators,
+
+		IncludeVCSInfo: *includeVCSInfo,
+
+		AdaptiveInterval: *adaptiveMode,
+		MaxInterval:      *maxInterval,
+		MaxBytes:         *maxBytes,
+		Count:            *count,
+		MaxRuntime:       *maxRuntime,
+		FailFast:         *failFast,
+		ManifestFile:     *manifestFile,
+
+		RampUp:            *rampDuration,
+		RampStartInterval: *rampStart,
+
+		PeerServices: peerServiceNames,
+
+		LogLevel:      *logLevel,
+		LogFormat:     *logFormat,
+		LogFieldNames: logKeys,
+
+		LogBufferSize:    *logBufferSize,
+		LogFlushInterval: *logFlushInterval,
+		TraceURLTemplate: *traceURLTemplate,
+
+		OTLPEndpoints:      parseList(*otlpEndpoint),
+		OTLPCACert:         *otlpCACert,
+		DisableOTLP:        *disableOTLP,
+		TraceFile:          *traceFile,
+		ExportOnErrorOnly:  *exportErrorsOnly,
+		SlowCycleThreshold: *slowThreshold,
+		MinSpanDuration:    *minSpanDuration,
+
+		OTLPQueueSize:        *otlpQueueSize,
+		OTLPBlockOnQueueFull: *otlpBlockOnFull,
+		RecordExportDuration: *exportDuration,
+		MaxSpansPerCycle:     *maxSpansPerCycle,
+
+		Accept:                 *accept,
+		NonErrorStatusCodes:    nonErrorStatusCodes,
+		ExpectedStatusCodes:    expectedStatusCodes,
+		FailureInjectionRate:   *injectionRate,
+		InjectedLatency:        *injectedLatency,
+		InjectedLatencyJitter:  *latencyJitter,
+		BearerTokenFile:        *bearerTokenFile,
+		CaptureResponseHeaders: parseList(*captureHeaders),
+		DNSCacheTTL:            *dnsCacheTTL,
+		MaxConcurrentDNS:       *maxConcurrentDNS,
+		DialTimeout:            *dialTimeout,
+		KeepAlive:              *keepAlive,
+		SocketReadTimeout:      *sockReadTimeout,
+		SocketWriteTimeout:     *sockWriteTimeout,
+		MaxRetries:             *maxRetries,
+		RetryBackoff:           *retryBackoff,
+		ScrubQueryParams:       parseList(*scrubParams),
+		ScrubAllQueryParams:    *scrubParams == "*",
+		MinTLSVersion:          *minTLSVersion,
+		CipherSuites:           parseList(*cipherSuites),
+
+		StatsDAddr: *statsdAddr,
+
+		PushgatewayURL: *pushgatewayURL,
+		PushInterval:   *pushInterval,
+		PushJob:        *pushJob,
+		PushInstance:   *pushInstance,
+		MetricsLabels:  constLabels,
+
+		HealthPort:            8080,
+		HealthMethods:         parseList(*healthMethods),
+		EnableTrigger:         *enableTrigger,
+		AdminToken:            *adminToken,
+		ReadyRequiresExport:   *readyNeedsExport,
+		ReadyChecksTarget:     *readyNeedsTarget,
+		ReadyWindow:           *readyWindow,
+		ReadyFailureThreshold: *readyMaxFailures,
+		ReadyDelay:            *readyDelay,
+	}
+	settings.BuildInfo = health.BuildInfo{Version: version, Commit: commit, Date: date}
+	if *benchmarkMode {
+		// Keep stdout for the report
+		settings.BenchmarkOutput = os.Stdout
+		settings.LogOutput = os.Stderr
+	}
+	if *curlMode {
+		// Keep stdout for the response
+		settings.CurlOutput = os.Stdout
+		settings.LogOutput = os.Stderr
+	}
+	if *emitTraceIDs {
+		settings.TraceIDOutput = os.Stdout
+	}
+	if *sampleRatio < 1 {
+		settings.TraceSampler = sdktrace.ParentBased(sdktrace.TraceIDRatioBased(*sampleRatio))
+	}
+	if *detailRatio < 1 {
+		settings.DetailSpanSampleRatio = detailRatio
+	}
+	return settings, nil
+}
+
+// parseStatusCodes parses a comma-separated list of HTTP status codes
+func parseStatusCodes(value string) ([]int, error) {
+	var statusCodes []int
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		code, err := strconv.Atoi(field)
+		if err != nil || code < 100 || code > 599 {
+			return nil, fmt.Errorf("invalid status code %q", field)
+		}
+		statusCodes = append(statusCodes, code)
+	}
+	return statusCodes, nil
+}
+
+// parsePeerServices parses a comma-separated list of service=url pairs
+func parsePeerServices(value string) (map[string]string, error) {
+	services := make(map[string]string)
+	for _, field := range parseList(value) {
+		name, url, ok := strings.Cut(field, "=")
+		if !ok || name == "" || url == "" {
+			return nil, fmt.Errorf("invalid peer service %q, expected service=url", field)
+		}
+		services[url] = name
+	}
+	return services, nil
+}
+
+// parseFieldNames parses a comma-separated list of default=custom log key pairs
+func parseFieldNames(value string) (map[string]string, error) {
+	names := make(map[string]string)
+	for _, field := range parseList(value) {
+		from, to, ok := strings.Cut(field, "=")
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("invalid field name %q, expected default=custom", field)
+		}
+		names[from] = to
+	}
+	return names, nil
+}
+
+// labelNamePattern matches valid Prometheus label names
+var labelNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// parseLabels parses a comma-separated list of name=value metric labels
+func parseLabels(value string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, field := range parseList(value) {
+		name, labelValue, ok := strings.Cut(field, "=")
+		if !ok || !labelNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid label %q, expected name=value", field)
+		}
+		if err := health.ValidateLabelName(name); err != nil {
+			return nil, err
+		}
+		labels[name] = labelValue
+	}
+	return labels, nil
+}
+
+// flagWasSet reports whether the named flag was given on the command line
+func flagWasSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// parseList splits a comma-separated list, dropping empty entries
+func parseList(value string) []string {
+	var items []string
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			items = append(items, field)
 		}
 	}
+	return items
 }
 
-func makeRequest(ctx context.Context, client *httpclient.Client, log *logger.Logger, tracer trace.Tracer, url string, requestCount int) {
-	// Create root span for the entire request cycle
-	ctx, span := tracer.Start(ctx, "request.cycle",
-		trace.WithAttributes(
-			attribute.String("service.name", *serviceName),
-			attribute.String("request.target_url", url),
-			attribute.Int64("request.interval_ms", interval.Milliseconds()),
-			attribute.Int("request.count", requestCount),
-		))
-	defer span.End()
+// requestResult describes the outcome of a single request cycle
+type requestResult struct {
+	success      bool
+	statusCode   int
+	requestSize  int
+	responseSize int
+	duration     time.Duration
+	traceID      string
+	sampled      bool
+	err          error
+}
+
+// triggerResult converts the result into the health server's trigger response
+func (r requestResult) triggerResult() health.TriggerResult {
+	result := health.TriggerResult{
+		StatusCode: r.statusCode,
+		DurationMs: r.duration.Milliseconds(),
+		TraceID:    r.traceID,
+	}
+	if r.err != nil {
+		result.Error = r.err.Error()
+	}
+	return result
+}
+
+func makeRequest(ctx context.Context, client *httpclient.Client, log *logger.Logger, tracer trace.Tracer, url string, requestCount int) requestResult {
+	// Reuse the caller's request ID or generate one for this cycle
+	requestID, ok := httpclient.RequestIDFromContext(ctx)
+	if !ok {
+		requestID = uuid.NewString()
+		ctx = httpclient.WithRequestID(ctx, requestID)
+	}
+
+	// Create root span for the entire request cycle, skipping the attribute
+	// work entirely when tracing is disabled. The placeholder is a fresh
+	// non-recording span so errors never land on a span the caller owns.
+	traced := client.TracingEnabled()
+	span := trace.SpanFromContext(context.Background())
+	if traced {
+		ctx, span = tracer.Start(ctx, "request.cycle",
+			trace.WithAttributes(
+				attribute.String("service.name", *serviceName),
+				attribute.String("request.target_url", client.ScrubURL(url)),
+				attribute.Int64("request.interval_ms", intervalFromContext(ctx).Milliseconds()),
+				attribute.Int("request.count", requestCount),
+				attribute.String("request.id", requestID),
+			))
+		defer span.End()
 
-	start := time.Now()
+		// Name the target service when it differs from the resource service
+		if name, ok := httpclient.PeerServiceFromContext(ctx); ok {
+			span.SetAttributes(attribute.String("peer.service", name))
+		}
+	}
+
+	// Log with trace context and request ID
+	traceCtx := log.WithTraceContext(
+		span.SpanContext().TraceID().String(),
+		span.SpanContext().SpanID().String(),
+	).With(zap.String("request_id", requestID), zap.Bool("sampled", span.SpanContext().IsSampled()))
+
+	clk := client.Clock()
+	start := clk.Now()
 
 	// Make HTTP request
 	resp, err := client.Get(ctx, url)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		
-		// Log with trace context
-		traceCtx := log.WithTraceContext(
-			span.SpanContext().TraceID().String(),
-			span.SpanContext().SpanID().String(),
-		)
 		traceCtx.Error("Request failed", zap.Error(err))
-		return
+		return requestResult{
+			duration: clk.Now().Sub(start),
+			traceID:  span.SpanContext().TraceID().String(),
+			sampled:  span.SpanContext().IsSampled(),
+			err:      err,
+		}
 	}
-	defer resp.Body.Close()
 
 	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	body, err := client.ReadBody(ctx, resp)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		
-		traceCtx := log.WithTraceContext(
-			span.SpanContext().TraceID().String(),
-			span.SpanContext().SpanID().String(),
-		)
 		traceCtx.Error("Failed to read response body", zap.Error(err))
-		return
+		return requestResult{
+			statusCode: resp.StatusCode,
+			duration:   clk.Now().Sub(start),
+			traceID:    span.SpanContext().TraceID().String(),
+			sampled:    span.SpanContext().IsSampled(),
+			err:        err,
+		}
 	}
 
-	duration := time.Since(start)
+	duration := clk.Now().Sub(start)
 
 	// Set span attributes and status
-	span.SetAttributes(
-		attribute.Int64("request.cycle.duration_ms", duration.Milliseconds()),
-		attribute.Bool("request.success", resp.StatusCode < 400),
-		attribute.Int("http.status_code", resp.StatusCode),
-		attribute.Int("response.size", len(body)),
-	)
-
-	if resp.StatusCode >= 400 {
-		span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", resp.StatusCode))
-		span.SetAttributes(attribute.String("request.error", fmt.Sprintf("HTTP %d", resp.StatusCode)))
-	} else {
-		span.SetStatus(codes.Ok, "")
-	}
+	if traced {
+		span.SetAttributes(
+			attribute.Int64("request.cycle.duration_ms", duration.Milliseconds()),
+			attribute.Bool("request.success", !client.IsErrorStatus(resp.StatusCode)),
+			attribute.Int("http.status_code", resp.StatusCode),
+			attribute.Int("response.size", len(body)),
+		)
 
-	// Log with trace context
-	traceCtx := log.WithTraceContext(
-		span.SpanContext().TraceID().String(),
-		span.SpanContext().SpanID().String(),
-	)
+		if client.IsErrorStatus(resp.StatusCode) {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", resp.StatusCode))
+			span.SetAttributes(attribute.String("request.error", fmt.Sprintf("HTTP %d", resp.StatusCode)))
+		} else {
+			span.SetStatus(codes.Ok, "")
+		}
+	}
 
-	if resp.StatusCode >= 400 {
+	if client.IsErrorStatus(resp.StatusCode) {
 		traceCtx.Warn("HTTP request returned error status",
-			zap.String("url", url),
+			zap.String("url", client.ScrubURL(url)),
 			zap.Int("status_code", resp.StatusCode),
 			zap.Int("response_size", len(body)),
 			zap.Duration("duration", duration))
 	} else {
 		traceCtx.Info("HTTP request completed successfully",
-			zap.String("url", url),
+			zap.String("url", client.ScrubURL(url)),
 			zap.Int("status_code", resp.StatusCode),
 			zap.Int("response_size", len(body)),
 			zap.Duration("duration", duration))
 	}
-}
\ No newline at end of file
+
+	requestSize := int(resp.Request.ContentLength)
+	if requestSize < 0 {
+		requestSize = 0
+	}
+
+	return requestResult{
+		success:      !client.IsErrorStatus(resp.StatusCode),
+		statusCode:   resp.StatusCode,
+		requestSize:  requestSize,
+		responseSize: len(body),
+		duration:     duration,
+		traceID:      span.SpanContext().TraceID().String(),
+		sampled:      span.SpanContext().IsSampled(),
+	}
+}
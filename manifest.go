@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"tracer-test/pkg/health"
+)
+
+// runManifest is the machine-readable record of a run written to
+// -manifest-file for downstream tooling
+type runManifest struct {
+	Config        map[string]string `json:"config"`
+	Build         health.BuildInfo  `json:"build"`
+	StartedAt     time.Time         `json:"started_at"`
+	EndedAt       time.Time         `json:"ended_at"`
+	TotalRequests int               `json:"total_requests"`
+	Stats         manifestStats     `json:"stats"`
+}
+
+// manifestStats are the aggregate outcomes of a run
+type manifestStats struct {
+	Successes        int            `json:"successes"`
+	Failures         int            `json:"failures"`
+	ErrorsByCategory map[string]int `json:"errors_by_category"`
+	TotalBytes       int64          `json:"total_bytes"`
+	DurationSeconds  float64        `json:"duration_seconds"`
+}
+
+// newRunManifest builds the manifest from the flags, with tokens redacted,
+// and the run statistics up to ended
+func newRunManifest(flags *flag.FlagSet, build health.BuildInfo, stats *runStats, ended time.Time) runManifest {
+	config := make(map[string]string)
+	flags.VisitAll(func(f *flag.Flag) {
+		config[f.Name] = redactedFlagValue(f)
+	})
+
+	return runManifest{
+		Config:        config,
+		Build:         build,
+		StartedAt:     stats.started,
+		EndedAt:       ended,
+		TotalRequests: stats.requests,
+		Stats: manifestStats{
+			Successes:        stats.successes,
+			Failures:         stats.failures,
+			ErrorsByCategory: stats.errors,
+			TotalBytes:       stats.bytes,
+			DurationSeconds:  ended.Sub(stats.started).Seconds(),
+		},
+	}
+}
+
+// writeManifest writes the manifest to path as indented JSON, replacing any
+// previous file
+func writeManifest(path string, manifest runManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
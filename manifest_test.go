@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"tracer-test/pkg/health"
+)
+
+func TestApp_Manifest(t *testing.T) {
+	// Create a stub target
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	var logs syncBuffer
+	app, err := NewApp(Settings{
+		URL:          server.URL,
+		Probe:        probeHTTP,
+		Interval:     10 * time.Millisecond,
+		Count:        2,
+		ManifestFile: path,
+		ServiceName:  "test-service",
+		LogLevel:     "info",
+		LogFormat:    "json",
+		LogOutput:    &logs,
+		DisableOTLP:  true,
+		BuildInfo:    health.BuildInfo{Version: "1.2.3"},
+	})
+	if err != nil {
+		t.Fatalf("NewApp() error = %v", err)
+	}
+	defer func() { _ = app.Shutdown(context.Background()) }()
+
+	if err := app.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+	var manifest map[string]json.RawMessage
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("Manifest is not valid JSON: %v\n%s", err, data)
+	}
+	for _, key := range []string{"config", "build", "started_at", "ended_at", "total_requests", "stats"} {
+		if _, ok := manifest[key]; !ok {
+			t.Errorf("Manifest is missing the %q key", key)
+		}
+	}
+
+	if got := string(manifest["total_requests"]); got != "2" {
+		t.Errorf("total_requests = %s, expected 2", got)
+	}
+	var build health.BuildInfo
+	if err := json.Unmarshal(manifest["build"], &build); err != nil || build.Version != "1.2.3" {
+		t.Errorf("build = %s, expected version 1.2.3", manifest["build"])
+	}
+	var config map[string]string
+	if err := json.Unmarshal(manifest["config"], &config); err != nil {
+		t.Fatalf("config is not a string map: %v", err)
+	}
+	if _, ok := config["manifest-file"]; !ok {
+		t.Error("Expected config to include the manifest-file flag")
+	}
+}
@@ -0,0 +1,38 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReal_Now(t *testing.T) {
+	before := time.Now()
+	now := Real{}.Now()
+	after := time.Now()
+
+	if now.Before(before) || now.After(after) {
+		t.Errorf("Real.Now() = %v, expected between %v and %v", now, before, after)
+	}
+}
+
+func TestFake(t *testing.T) {
+	start := time.Date(2024, 1, 15, 10, 30, 45, 0, time.UTC)
+	fake := NewFake(start)
+
+	// Test initial time
+	if !fake.Now().Equal(start) {
+		t.Errorf("Fake.Now() = %v, expected %v", fake.Now(), start)
+	}
+
+	// Test advancing
+	fake.Advance(5 * time.Second)
+	if expected := start.Add(5 * time.Second); !fake.Now().Equal(expected) {
+		t.Errorf("Fake.Now() after Advance = %v, expected %v", fake.Now(), expected)
+	}
+
+	// Test setting
+	fake.Set(start)
+	if !fake.Now().Equal(start) {
+		t.Errorf("Fake.Now() after Set = %v, expected %v", fake.Now(), start)
+	}
+}
@@ -0,0 +1,44 @@
+package health
+
+import "encoding/json"
+
+// CheckFunc reports why a dependency is unusable, or nil when it is usable.
+// It runs on every /ready request, so it should return a cached result
+// rather than probe the dependency itself.
+type CheckFunc func() error
+
+// ReadyCheck is a named dependency check that /ready requires to pass
+type ReadyCheck struct {
+	Name  string
+	Check CheckFunc
+}
+
+// runChecks runs every configured check, returning each one's status as
+// "ok" or the failure message, and whether all of them passed
+func (s *Server) runChecks() (map[string]string, bool) {
+	if len(s.config.ReadyChecks) == 0 {
+		return nil, true
+	}
+
+	passed := true
+	results := make(map[string]string, len(s.config.ReadyChecks))
+	for _, check := range s.config.ReadyChecks {
+		if err := check.Check(); err != nil {
+			results[check.Name] = err.Error()
+			passed = false
+			continue
+		}
+		results[check.Name] = "ok"
+	}
+	return results, passed
+}
+
+// checksJSON renders check results as the "checks" field of /ready, or
+// returns an empty string when there are none
+func checksJSON(results map[string]string) string {
+	if len(results) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(results)
+	return `,"checks":` + string(data)
+}
@@ -0,0 +1,55 @@
+package health
+
+import (
+	"sort"
+	"sync"
+)
+
+// ConnCounts holds the idle and active connection counts for one host
+type ConnCounts struct {
+	Idle   int
+	Active int
+}
+
+// connections holds the latest per-host connection pool counts
+type connections struct {
+	mu    sync.Mutex
+	hosts map[string]ConnCounts
+}
+
+// set replaces the counts with a copy of hosts
+func (c *connections) set(hosts map[string]ConnCounts) {
+	copied := make(map[string]ConnCounts, len(hosts))
+	for host, counts := range hosts {
+		copied[host] = counts
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hosts = copied
+}
+
+// write writes the idle and active gauges, one series per host sorted by host
+func (c *connections) write(m *metricWriter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	hosts := make([]string, 0, len(c.hosts))
+	for host := range c.hosts {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	for _, host := range hosts {
+		m.intSample("http_connections_idle", int64(c.hosts[host].Idle), label{"host", host})
+	}
+	for _, host := range hosts {
+		m.intSample("http_connections_active", int64(c.hosts[host].Active), label{"host", host})
+	}
+}
+
+// SetConnections sets the HTTP client's idle and active connection counts,
+// keyed by host:port
+func (s *Server) SetConnections(hosts map[string]ConnCounts) {
+	s.conns.set(hosts)
+}
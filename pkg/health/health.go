@@ -3,37 +3,157 @@ package health
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
+	"slices"
+	"strings"
 	"sync/atomic"
 	"time"
+
+	"tracer-test/pkg/clock"
 )
 
 // Server provides health check endpoints
 type Server struct {
 	server   *http.Server
+	clock    clock.Clock
 	ready    int32
 	requests int64
+	inFlight inFlight
+
+	exportHealthy int32
+	spansDropped  int64
+
+	tracesSampled int64
+	tracesDropped int64
+
+	requestSizes  *histogram
+	responseSizes *histogram
+
+	requestDurations *percentiles
+
+	dnsDurations *histogram
+	dnsFailures  int64
+	// retriesExhausted counts requests that failed after their last retry
+	retriesExhausted int64
+
+	conns connections
+
+	exportDurations *histogram
+
+	loopStalls int64
+
+	requestInterval int64
+
+	maintenance int32
+
+	outcomes *outcomeWindow
+
+	config Config
+}
+
+// defaultMethods are the methods accepted by the read-only endpoints
+var defaultMethods = []string{http.MethodGet, http.MethodHead}
+
+// Config holds health server configuration
+type Config struct {
+	Port int
+	// Methods lists the HTTP methods accepted by /health, /ready, /metrics
+	// and /info, e.g. adding POST for probing systems that POST. Others get
+	// 405 Method Not Allowed. Defaults to GET and HEAD.
+	Methods []string
+	// AuthToken, when set, is required as a bearer token on administrative
+	// endpoints such as /trigger
+	AuthToken string
+	// EnableTrigger exposes POST /trigger, which runs Trigger on demand
+	EnableTrigger bool
+	Trigger       TriggerFunc
+	// SetInterval, when set together with AuthToken, exposes PUT /interval
+	// to change the request interval at runtime
+	SetInterval IntervalFunc
+	// SetMaintenance, when set together with AuthToken, exposes POST
+	// /maintenance to pause the request loop and report not-ready until
+	// maintenance is turned off
+	SetMaintenance MaintenanceFunc
+	// ReadinessWindow, when positive, derives readiness from the last
+	// ReadinessWindow request outcomes: the server is not ready while their
+	// failure rate exceeds ReadinessFailureThreshold. SetReady(false) still
+	// forces not-ready.
+	ReadinessWindow           int
+	ReadinessFailureThreshold float64
+	// ReadyChecks must all pass for the server to be ready. /ready lists
+	// each check's result under "checks".
+	ReadyChecks []ReadyCheck
+	// PushgatewayURL, when set, enables Push to send the metrics to a
+	// Prometheus Pushgateway under the PushJob (default "tracer_test") and
+	// optional PushInstance labels. RunPush pushes every PushInterval.
+	PushgatewayURL string
+	PushInterval   time.Duration
+	PushJob        string
+	PushInstance   string
+	// BuildInfo is reported by /info alongside the Go and dependency versions
+	BuildInfo BuildInfo
+	// ConstLabels (e.g. service, environment, instance) are added to every
+	// metric series written by WriteMetrics. Names are unchanged, so empty
+	// labels keep the plain unlabeled output.
+	ConstLabels map[string]string
 }
 
 // New creates a new health server
 func New(port int) *Server {
+	return NewWithConfig(Config{Port: port})
+}
+
+// NewWithConfig creates a new health server from config
+func NewWithConfig(config Config) *Server {
 	mux := http.NewServeMux()
 	
 	server := &Server{
 		server: &http.Server{
-			Addr:    fmt.Sprintf(":%d", port),
+			Addr:    fmt.Sprintf(":%d", config.Port),
 			Handler: mux,
 		},
+		config:        config,
+		clock:         clock.Real{},
+		exportHealthy: 1,
+		requestSizes:  newHistogram(sizeBuckets),
+		responseSizes: newHistogram(sizeBuckets),
+		dnsDurations:  newHistogram(durationBuckets),
+
+		exportDurations: newHistogram(durationBuckets),
+
+		requestDurations: newPercentiles(),
+	}
+	if config.ReadinessWindow > 0 {
+		server.outcomes = newOutcomeWindow(config.ReadinessWindow)
 	}
 
 	// Health check endpoint
-	mux.HandleFunc("/health", server.healthHandler)
+	mux.HandleFunc("/health", server.allowMethods(server.healthHandler))
 	
 	// Readiness check endpoint
-	mux.HandleFunc("/ready", server.readyHandler)
+	mux.HandleFunc("/ready", server.allowMethods(server.readyHandler))
 	
 	// Simple metrics endpoint
-	mux.HandleFunc("/metrics", server.metricsHandler)
+	mux.HandleFunc("/metrics", server.allowMethods(server.metricsHandler))
+
+	// Build and dependency versions
+	mux.HandleFunc("/info", server.allowMethods(server.infoHandler))
+
+	// On-demand request endpoint
+	if config.EnableTrigger && config.Trigger != nil {
+		mux.HandleFunc("/trigger", server.requireAuth(server.triggerHandler))
+	}
+
+	// Runtime interval endpoint
+	if config.SetInterval != nil && config.AuthToken != "" {
+		mux.HandleFunc("/interval", server.requireAuth(server.intervalHandler))
+	}
+
+	// Maintenance mode endpoint
+	if config.SetMaintenance != nil && config.AuthToken != "" {
+		mux.HandleFunc("/maintenance", server.requireAuth(server.maintenanceHandler))
+	}
 
 	return server
 }
@@ -48,6 +168,11 @@ func (s *Server) Stop(ctx context.Context) error {
 	return s.server.Shutdown(ctx)
 }
 
+// SetClock replaces the clock used for response timestamps
+func (s *Server) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
 // SetReady sets the readiness status
 func (s *Server) SetReady(ready bool) {
 	if ready {
@@ -57,48 +182,204 @@ func (s *Server) SetReady(ready bool) {
 	}
 }
 
+// SetExportHealthy sets whether the trace export pipeline is healthy
+func (s *Server) SetExportHealthy(healthy bool) {
+	if healthy {
+		atomic.StoreInt32(&s.exportHealthy, 1)
+	} else {
+		atomic.StoreInt32(&s.exportHealthy, 0)
+	}
+}
+
+// SetSpansDropped sets the number of spans dropped by full export queues
+func (s *Server) SetSpansDropped(dropped int64) {
+	atomic.StoreInt64(&s.spansDropped, dropped)
+}
+
+// SetRetriesExhausted sets the number of requests that failed after using
+// up all of their retries
+func (s *Server) SetRetriesExhausted(exhausted int64) {
+	atomic.StoreInt64(&s.retriesExhausted, exhausted)
+}
+
+// RecordSampling counts a request cycle's trace as sampled or dropped
+func (s *Server) RecordSampling(sampled bool) {
+	if sampled {
+		atomic.AddInt64(&s.tracesSampled, 1)
+	} else {
+		atomic.AddInt64(&s.tracesDropped, 1)
+	}
+}
+
+// StartRequest marks a request as in flight. Call FinishRequest when it completes.
+func (s *Server) StartRequest() {
+	s.inFlight.start()
+}
+
+// FinishRequest marks an in-flight request as completed
+func (s *Server) FinishRequest() {
+	s.inFlight.finish()
+}
+
+// RecordOutcome records whether a request succeeded for derived readiness
+func (s *Server) RecordOutcome(success bool) {
+	if s.outcomes != nil {
+		s.outcomes.record(success)
+	}
+}
+
+// isReady reports the readiness flag combined with maintenance mode and the
+// recent failure rate
+func (s *Server) isReady() bool {
+	if atomic.LoadInt32(&s.ready) != 1 || s.InMaintenance() {
+		return false
+	}
+	if _, passed := s.runChecks(); !passed {
+		return false
+	}
+	if s.outcomes == nil {
+		return true
+	}
+	return s.outcomes.failureRate() <= s.config.ReadinessFailureThreshold
+}
+
 // IncrementRequests increments the request counter
 func (s *Server) IncrementRequests() {
 	atomic.AddInt64(&s.requests, 1)
 }
 
+// ObserveSizes records the request and response payload sizes in bytes
+func (s *Server) ObserveSizes(requestSize, responseSize int) {
+	s.requestSizes.Observe(float64(requestSize))
+	s.responseSizes.Observe(float64(responseSize))
+}
+
+// ObserveDuration records a request duration for percentile estimation
+func (s *Server) ObserveDuration(duration time.Duration) {
+	s.requestDurations.Observe(duration.Seconds())
+}
+
+// ObserveDNSResolution records a DNS probe's resolution time, counting failures
+func (s *Server) ObserveDNSResolution(duration time.Duration, failed bool) {
+	if failed {
+		atomic.AddInt64(&s.dnsFailures, 1)
+		return
+	}
+	s.dnsDurations.Observe(duration.Seconds())
+}
+
+// ObserveExportDuration records how long one span export took
+func (s *Server) ObserveExportDuration(duration time.Duration) {
+	s.exportDurations.Observe(duration.Seconds())
+}
+
+// IncrementLoopStalls counts a request loop stall detected by the watchdog
+func (s *Server) IncrementLoopStalls() {
+	atomic.AddInt64(&s.loopStalls, 1)
+}
+
+// SetRequestInterval records the current effective request interval
+func (s *Server) SetRequestInterval(interval time.Duration) {
+	atomic.StoreInt64(&s.requestInterval, int64(interval))
+}
+
 // GetAddr returns the server address
 func (s *Server) GetAddr() string {
 	return s.server.Addr
 }
 
-// healthHandler handles /health endpoint
+// allowMethods rejects requests whose method is not in the configured
+// Methods with 405 and an Allow header
+func (s *Server) allowMethods(next http.HandlerFunc) http.HandlerFunc {
+	methods := defaultMethods
+	if len(s.config.Methods) > 0 {
+		methods = make([]string, 0, len(s.config.Methods))
+		for _, method := range s.config.Methods {
+			methods = append(methods, strings.ToUpper(method))
+		}
+	}
+	allow := strings.Join(methods, ", ")
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !slices.Contains(methods, r.Method) {
+			w.Header().Set("Allow", allow)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// healthHandler handles /health endpoint. HEAD requests get no body.
 func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	_, _ = fmt.Fprintf(w, `{"status":"healthy","timestamp":"%s"}`, time.Now().Format(time.RFC3339))
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = fmt.Fprintf(w, `{"status":"healthy","timestamp":"%s"}`, s.clock.Now().Format(time.RFC3339))
 }
 
-// readyHandler handles /ready endpoint
+// readyHandler handles /ready endpoint. HEAD requests get no body.
 func (s *Server) readyHandler(w http.ResponseWriter, r *http.Request) {
-	ready := atomic.LoadInt32(&s.ready)
-	
 	w.Header().Set("Content-Type", "application/json")
 	
-	if ready == 1 {
-		w.WriteHeader(http.StatusOK)
-		_, _ = fmt.Fprintf(w, `{"status":"ready","timestamp":"%s"}`, time.Now().Format(time.RFC3339))
-	} else {
-		w.WriteHeader(http.StatusServiceUnavailable)
-		_, _ = fmt.Fprintf(w, `{"status":"not_ready","timestamp":"%s"}`, time.Now().Format(time.RFC3339))
+	status, code := "ready", http.StatusOK
+	if !s.isReady() {
+		status, code = "not_ready", http.StatusServiceUnavailable
+	}
+	w.WriteHeader(code)
+	// HEAD probes only need the status
+	if r.Method == http.MethodHead {
+		return
 	}
+	checks, _ := s.runChecks()
+	_, _ = fmt.Fprintf(w, `{"status":"%s","timestamp":"%s"%s}`, status, s.clock.Now().Format(time.RFC3339), checksJSON(checks))
 }
 
 // metricsHandler handles /metrics endpoint
 func (s *Server) metricsHandler(w http.ResponseWriter, r *http.Request) {
-	requests := atomic.LoadInt64(&s.requests)
-	ready := atomic.LoadInt32(&s.ready)
-	
 	w.Header().Set("Content-Type", "text/plain")
 	w.WriteHeader(http.StatusOK)
-	
-	_, _ = fmt.Fprintf(w, `# HTTP Client Metrics
-http_requests_total %d
-service_ready %d
-`, requests, ready)
+
+	s.WriteMetrics(w)
+}
+
+// WriteMetrics writes the current metrics in the /metrics text format, with
+// the configured constant labels on every series
+func (s *Server) WriteMetrics(w io.Writer) {
+	m := newMetricWriter(w, s.config.ConstLabels)
+
+	ready := int64(0)
+	if s.isReady() {
+		ready = 1
+	}
+
+	m.comment("HTTP Client Metrics")
+	m.intSample("http_requests_total", atomic.LoadInt64(&s.requests))
+	m.intSample("service_ready", ready)
+	m.intSample("tracer_export_healthy", int64(atomic.LoadInt32(&s.exportHealthy)))
+	m.intSample("otlp_spans_dropped_total", atomic.LoadInt64(&s.spansDropped))
+	m.intSample("traces_sampled_total", atomic.LoadInt64(&s.tracesSampled))
+	m.intSample("traces_dropped_total", atomic.LoadInt64(&s.tracesDropped))
+	m.intSample("loop_stalls_total", atomic.LoadInt64(&s.loopStalls))
+	m.intSample("http_requests_in_flight", s.inFlight.current.Load())
+	m.intSample("http_requests_in_flight_max", s.inFlight.max.Load())
+
+	s.requestSizes.write(m, "http_request_size_bytes")
+	s.responseSizes.write(m, "http_response_size_bytes")
+	s.requestDurations.write(m, "http_request_duration", "seconds")
+	s.dnsDurations.write(m, "dns_resolution_duration_seconds")
+	m.intSample("dns_resolution_failures_total", atomic.LoadInt64(&s.dnsFailures))
+	m.intSample("http_retries_exhausted_total", atomic.LoadInt64(&s.retriesExhausted))
+	s.conns.write(m)
+	s.exportDurations.write(m, "otlp_export_duration_seconds")
+	interval := time.Duration(atomic.LoadInt64(&s.requestInterval)).Seconds()
+	rate := 0.0
+	if interval > 0 {
+		rate = 1 / interval
+	}
+	m.floatSample("request_interval_seconds", interval)
+	m.floatSample("request_rate_per_second", rate)
+	m.intSample("maintenance_mode", int64(atomic.LoadInt32(&s.maintenance)))
 }
@@ -2,12 +2,22 @@ package health
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"tracer-test/pkg/clock"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestNew(t *testing.T) {
@@ -99,6 +109,138 @@ func TestServer_healthHandler(t *testing.T) {
 	}
 }
 
+func TestServer_healthHandler_Head(t *testing.T) {
+	server := New(8080)
+
+	// Create HEAD request
+	req := httptest.NewRequest("HEAD", "/health", nil)
+	w := httptest.NewRecorder()
+
+	// Call handler
+	server.healthHandler(w, req)
+
+	// Check response
+	if w.Code != http.StatusOK {
+		t.Errorf("healthHandler() status = %d, expected %d", w.Code, http.StatusOK)
+	}
+
+	// Check content type
+	contentType := w.Header().Get("Content-Type")
+	if contentType != "application/json" {
+		t.Errorf("healthHandler() content type = %s, expected application/json", contentType)
+	}
+
+	// Check response body
+	if w.Body.Len() != 0 {
+		t.Errorf("healthHandler() body = %q, expected no body", w.Body.String())
+	}
+}
+
+func TestServer_readyHandler_Head(t *testing.T) {
+	server := New(8080)
+	server.SetReady(false)
+
+	// Create HEAD request
+	req := httptest.NewRequest("HEAD", "/ready", nil)
+	w := httptest.NewRecorder()
+
+	// Call handler
+	server.readyHandler(w, req)
+
+	// Check response
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("readyHandler() status = %d, expected %d", w.Code, http.StatusServiceUnavailable)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("readyHandler() body = %q, expected no body", w.Body.String())
+	}
+}
+
+func TestServer_infoHandler(t *testing.T) {
+	server := NewWithConfig(Config{
+		Port:      8080,
+		BuildInfo: BuildInfo{Version: "1.2.3", Commit: "abc123"},
+	})
+
+	// Create test request
+	req := httptest.NewRequest("GET", "/info", nil)
+	w := httptest.NewRecorder()
+
+	// Call handler
+	server.infoHandler(w, req)
+
+	// Check response
+	if w.Code != http.StatusOK {
+		t.Errorf("infoHandler() status = %d, expected %d", w.Code, http.StatusOK)
+	}
+
+	var response struct {
+		Version      string            `json:"version"`
+		Commit       string            `json:"commit"`
+		GoVersion    string            `json:"go_version"`
+		Dependencies map[string]string `json:"dependencies"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response JSON: %v", err)
+	}
+	if response.Version != "1.2.3" || response.Commit != "abc123" {
+		t.Errorf("infoHandler() build = %s/%s, expected 1.2.3/abc123", response.Version, response.Commit)
+	}
+	if !strings.HasPrefix(response.GoVersion, "go") {
+		t.Errorf("infoHandler() go_version = %q, expected a Go version", response.GoVersion)
+	}
+	if response.Dependencies["go.opentelemetry.io/otel/sdk"] == "" {
+		t.Errorf("infoHandler() dependencies = %v, expected the OTel SDK version", response.Dependencies)
+	}
+}
+
+func TestServer_AllowedMethods(t *testing.T) {
+	server := New(8080)
+
+	// DELETE is rejected with the allowed methods
+	req := httptest.NewRequest("DELETE", "/health", nil)
+	w := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE /health status = %d, expected %d", w.Code, http.StatusMethodNotAllowed)
+	}
+	if allow := w.Header().Get("Allow"); allow != "GET, HEAD" {
+		t.Errorf("Allow header = %q, expected %q", allow, "GET, HEAD")
+	}
+
+	// GET still works
+	req = httptest.NewRequest("GET", "/health", nil)
+	w = httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("GET /health status = %d, expected %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestServer_AllowedMethods_Configured(t *testing.T) {
+	server := NewWithConfig(Config{Port: 8080, Methods: []string{"get", "post"}})
+
+	tests := []struct {
+		method         string
+		expectedStatus int
+	}{
+		{"GET", http.StatusOK},
+		{"POST", http.StatusOK},
+		{"HEAD", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/health", nil)
+			w := httptest.NewRecorder()
+			server.server.Handler.ServeHTTP(w, req)
+			if w.Code != tt.expectedStatus {
+				t.Errorf("%s /health status = %d, expected %d", tt.method, w.Code, tt.expectedStatus)
+			}
+		})
+	}
+}
+
 func TestServer_readyHandler_Ready(t *testing.T) {
 	server := New(8080)
 	server.SetReady(true)
@@ -307,3 +449,877 @@ func TestServer_Integration(t *testing.T) {
 		t.Errorf("Stop() error = %v", err)
 	}
 }
+
+func TestServer_healthHandler_FakeClock(t *testing.T) {
+	server := New(8080)
+	server.SetClock(clock.NewFake(time.Date(2024, 1, 15, 10, 30, 45, 0, time.UTC)))
+
+	// Create test request
+	req := httptest.NewRequest("GET", "/health", nil)
+	w := httptest.NewRecorder()
+
+	// Call handler
+	server.healthHandler(w, req)
+
+	// Check exact response body
+	expected := `{"status":"healthy","timestamp":"2024-01-15T10:30:45Z"}`
+	if body := w.Body.String(); body != expected {
+		t.Errorf("healthHandler() body = %s, expected %s", body, expected)
+	}
+}
+
+func TestServer_readyHandler_FakeClock(t *testing.T) {
+	server := New(8080)
+	server.SetClock(clock.NewFake(time.Date(2024, 1, 15, 10, 30, 45, 0, time.UTC)))
+	server.SetReady(true)
+
+	// Create test request
+	req := httptest.NewRequest("GET", "/ready", nil)
+	w := httptest.NewRecorder()
+
+	// Call handler
+	server.readyHandler(w, req)
+
+	// Check exact response body
+	expected := `{"status":"ready","timestamp":"2024-01-15T10:30:45Z"}`
+	if body := w.Body.String(); body != expected {
+		t.Errorf("readyHandler() body = %s, expected %s", body, expected)
+	}
+}
+
+func TestServer_metricsHandler_SizeHistograms(t *testing.T) {
+	server := New(8080)
+
+	// Record a couple of sizes
+	server.ObserveSizes(0, 50)
+	server.ObserveSizes(200, 5000)
+
+	// Create test request
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+
+	// Call handler
+	server.metricsHandler(w, req)
+
+	// Check bucket counts
+	body := w.Body.String()
+	expected := []string{
+		`http_request_size_bytes_bucket{le="100"} 1`,
+		`http_request_size_bytes_bucket{le="1000"} 2`,
+		`http_request_size_bytes_count 2`,
+		`http_response_size_bytes_bucket{le="100"} 1`,
+		`http_response_size_bytes_bucket{le="1000"} 1`,
+		`http_response_size_bytes_bucket{le="10000"} 2`,
+		`http_response_size_bytes_bucket{le="+Inf"} 2`,
+		`http_response_size_bytes_sum 5050`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(body, line) {
+			t.Errorf("metricsHandler() body = %s, expected to contain %q", body, line)
+		}
+	}
+}
+
+func TestServer_triggerHandler(t *testing.T) {
+	server := NewWithConfig(Config{
+		Port:          8080,
+		AuthToken:     "secret",
+		EnableTrigger: true,
+		Trigger: func(ctx context.Context, override *TriggerRequest) TriggerResult {
+			return TriggerResult{
+				StatusCode: http.StatusOK,
+				DurationMs: 42,
+				TraceID:    "4bf92f3577b34da6a3ce929d0e0e4736",
+			}
+		},
+	})
+
+	// Test that requests without the token are rejected
+	req := httptest.NewRequest("POST", "/trigger", nil)
+	w := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("trigger without token status = %d, expected %d", w.Code, http.StatusUnauthorized)
+	}
+
+	// Test that only POST is allowed
+	req = httptest.NewRequest("GET", "/trigger", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /trigger status = %d, expected %d", w.Code, http.StatusMethodNotAllowed)
+	}
+
+	// Test a successful trigger
+	req = httptest.NewRequest("POST", "/trigger", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST /trigger status = %d, expected %d", w.Code, http.StatusOK)
+	}
+
+	var result TriggerResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("POST /trigger body %q is not valid JSON: %v", w.Body.String(), err)
+	}
+	if result.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("POST /trigger trace_id = %q, expected stub trace id", result.TraceID)
+	}
+	if result.StatusCode != http.StatusOK {
+		t.Errorf("POST /trigger status_code = %d, expected %d", result.StatusCode, http.StatusOK)
+	}
+
+	// Check that the triggered request was counted
+	if count := atomic.LoadInt64(&server.requests); count != 1 {
+		t.Errorf("Request count after trigger = %d, expected 1", count)
+	}
+}
+
+func TestServer_triggerHandler_CallerContext(t *testing.T) {
+	var traceID, requestID string
+	server := NewWithConfig(Config{
+		Port:          8080,
+		EnableTrigger: true,
+		Trigger: func(ctx context.Context, override *TriggerRequest) TriggerResult {
+			traceID = trace.SpanContextFromContext(ctx).TraceID().String()
+			requestID, _ = RequestIDFromContext(ctx)
+			return TriggerResult{StatusCode: http.StatusOK}
+		},
+	})
+
+	// Post with the caller's trace context and request ID
+	req := httptest.NewRequest("POST", "/trigger", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set("X-Request-ID", "caller-42")
+	w := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST /trigger status = %d, expected %d", w.Code, http.StatusOK)
+	}
+
+	if traceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Trigger context trace ID = %q, expected the traceparent trace ID", traceID)
+	}
+	if requestID != "caller-42" {
+		t.Errorf("RequestIDFromContext() = %q, expected %q", requestID, "caller-42")
+	}
+}
+
+func TestServer_triggerHandler_Override(t *testing.T) {
+	var received *TriggerRequest
+	config := Config{
+		Port:          8080,
+		AuthToken:     "secret",
+		EnableTrigger: true,
+		Trigger: func(ctx context.Context, override *TriggerRequest) TriggerResult {
+			received = override
+			return TriggerResult{StatusCode: http.StatusOK}
+		},
+	}
+	server := NewWithConfig(config)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedMethod string
+	}{
+		{"valid override", `{"method":"post","url":"https://example.com/api","headers":{"X-Test":"1"}}`, http.StatusOK, http.MethodPost},
+		{"default method", `{"url":"http://example.com"}`, http.StatusOK, http.MethodGet},
+		{"invalid json", `{"url":`, http.StatusBadRequest, ""},
+		{"unknown field", `{"url":"http://example.com","body":"x"}`, http.StatusBadRequest, ""},
+		{"unsupported method", `{"method":"TRACE","url":"http://example.com"}`, http.StatusBadRequest, ""},
+		{"relative url", `{"url":"/api"}`, http.StatusBadRequest, ""},
+		{"non-http scheme", `{"url":"file:///etc/passwd"}`, http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = nil
+			req := httptest.NewRequest("POST", "/trigger", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer secret")
+			w := httptest.NewRecorder()
+			server.server.Handler.ServeHTTP(w, req)
+			if w.Code != tt.expectedStatus {
+				t.Errorf("POST /trigger status = %d, expected %d", w.Code, tt.expectedStatus)
+			}
+			if tt.expectedMethod == "" {
+				return
+			}
+			if received == nil {
+				t.Fatal("Trigger received no override, expected one")
+			}
+			if received.Method != tt.expectedMethod {
+				t.Errorf("Override method = %s, expected %s", received.Method, tt.expectedMethod)
+			}
+		})
+	}
+
+	// Overrides are refused without an admin token
+	config.AuthToken = ""
+	server = NewWithConfig(config)
+	req := httptest.NewRequest("POST", "/trigger", strings.NewReader(`{"url":"http://example.com"}`))
+	w := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("POST /trigger override without token status = %d, expected %d", w.Code, http.StatusForbidden)
+	}
+}
+
+func TestServer_triggerHandler_Disabled(t *testing.T) {
+	server := New(8080)
+
+	req := httptest.NewRequest("POST", "/trigger", nil)
+	w := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("POST /trigger status = %d, expected %d when disabled", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestServer_metricsHandler_ExportHealthy(t *testing.T) {
+	server := New(8080)
+
+	// Test the default healthy state
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	server.metricsHandler(w, req)
+	if body := w.Body.String(); !strings.Contains(body, "tracer_export_healthy 1") {
+		t.Errorf("metricsHandler() body = %s, expected to contain 'tracer_export_healthy 1'", body)
+	}
+
+	// Test an export outage
+	server.SetExportHealthy(false)
+	w = httptest.NewRecorder()
+	server.metricsHandler(w, req)
+	if body := w.Body.String(); !strings.Contains(body, "tracer_export_healthy 0") {
+		t.Errorf("metricsHandler() body = %s, expected to contain 'tracer_export_healthy 0'", body)
+	}
+}
+
+func TestServer_metricsHandler_ConstLabels(t *testing.T) {
+	server := NewWithConfig(Config{
+		Port: 8080,
+		ConstLabels: map[string]string{
+			"service":     "http-client",
+			"environment": "prod",
+			"instance":    `pod "a"`,
+		},
+	})
+	server.IncrementRequests()
+	server.ObserveSizes(10, 100)
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	server.metricsHandler(w, req)
+	body := w.Body.String()
+
+	// Labels are sorted by name and values escaped
+	labels := `environment="prod",instance="pod \"a\"",service="http-client"`
+	for _, line := range []string{
+		"http_requests_total{" + labels + "} 1\n",
+		"http_request_size_bytes_bucket{" + labels + `,le="+Inf"} 1` + "\n",
+		"request_interval_seconds{" + labels + "} 0\n",
+		"# HTTP Client Metrics\n",
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("Metrics body = %q, expected %q", body, line)
+		}
+	}
+	if strings.Contains(body, "http_requests_total 1") {
+		t.Error("Expected no unlabeled http_requests_total series")
+	}
+}
+
+func TestServer_metricsHandler_ConstLabelsWithSeriesLabels(t *testing.T) {
+	server := NewWithConfig(Config{Port: 8080, ConstLabels: map[string]string{"service": "api"}})
+	// Series label values may contain braces, commas and quotes
+	server.SetConnections(map[string]ConnCounts{`odd},host="x",:443`: {Idle: 1}})
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	server.metricsHandler(w, req)
+
+	expected := `http_connections_idle{service="api",host="odd},host=\"x\",:443"} 1` + "\n"
+	if body := w.Body.String(); !strings.Contains(body, expected) {
+		t.Errorf("metricsHandler() body = %s, expected to contain %q", body, expected)
+	}
+}
+
+func TestServer_metricsHandler_RequestRate(t *testing.T) {
+	server := New(8080)
+	server.SetRequestInterval(250 * time.Millisecond)
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	server.metricsHandler(w, req)
+	for _, line := range []string{"request_interval_seconds 0.25\n", "request_rate_per_second 4\n"} {
+		if body := w.Body.String(); !strings.Contains(body, line) {
+			t.Errorf("metricsHandler() body = %s, expected to contain %q", body, line)
+		}
+	}
+}
+
+func TestServer_metricsHandler_SpansDropped(t *testing.T) {
+	server := New(8080)
+	server.SetSpansDropped(42)
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	server.metricsHandler(w, req)
+	if body := w.Body.String(); !strings.Contains(body, "otlp_spans_dropped_total 42\n") {
+		t.Errorf("metricsHandler() body = %s, expected to contain 'otlp_spans_dropped_total 42'", body)
+	}
+}
+
+func TestServer_metricsHandler_DNSResolution(t *testing.T) {
+	server := New(8080)
+
+	// Record one fast resolution and one failure
+	server.ObserveDNSResolution(3*time.Millisecond, false)
+	server.ObserveDNSResolution(0, true)
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	server.metricsHandler(w, req)
+
+	body := w.Body.String()
+	expected := []string{
+		`dns_resolution_duration_seconds_bucket{le="0.001"} 0`,
+		`dns_resolution_duration_seconds_bucket{le="0.005"} 1`,
+		`dns_resolution_duration_seconds_count 1`,
+		`dns_resolution_failures_total 1`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(body, line) {
+			t.Errorf("metricsHandler() body = %s, expected to contain %q", body, line)
+		}
+	}
+}
+
+func TestServer_metricsHandler_ExportDuration(t *testing.T) {
+	server := New(8080)
+
+	// Record one export slowed down by the collector
+	server.ObserveExportDuration(150 * time.Millisecond)
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	server.metricsHandler(w, req)
+
+	body := w.Body.String()
+	expected := []string{
+		`otlp_export_duration_seconds_bucket{le="0.1"} 0`,
+		`otlp_export_duration_seconds_bucket{le="0.25"} 1`,
+		`otlp_export_duration_seconds_sum 0.15`,
+		`otlp_export_duration_seconds_count 1`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(body, line) {
+			t.Errorf("metricsHandler() body = %s, expected to contain %q", body, line)
+		}
+	}
+}
+
+func TestServer_IncrementLoopStalls(t *testing.T) {
+	server := New(8080)
+	server.IncrementLoopStalls()
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	server.metricsHandler(w, req)
+
+	if body := w.Body.String(); !strings.Contains(body, "loop_stalls_total 1") {
+		t.Errorf("metricsHandler() body = %s, expected to contain 'loop_stalls_total 1'", body)
+	}
+}
+
+func TestServer_SetRetriesExhausted(t *testing.T) {
+	server := New(8080)
+	server.SetRetriesExhausted(3)
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	server.metricsHandler(w, req)
+
+	if body := w.Body.String(); !strings.Contains(body, "http_retries_exhausted_total 3\n") {
+		t.Errorf("metricsHandler() body = %s, expected to contain 'http_retries_exhausted_total 3'", body)
+	}
+}
+
+func TestServer_SetConnections(t *testing.T) {
+	server := NewWithConfig(Config{Port: 8080, ConstLabels: map[string]string{"service": "api"}})
+	server.SetConnections(map[string]ConnCounts{
+		"b.example.com:443": {Idle: 1, Active: 0},
+		"a.example.com:443": {Idle: 2, Active: 1},
+	})
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	server.metricsHandler(w, req)
+
+	expected := `http_connections_idle{service="api",host="a.example.com:443"} 2
+http_connections_idle{service="api",host="b.example.com:443"} 1
+http_connections_active{service="api",host="a.example.com:443"} 1
+http_connections_active{service="api",host="b.example.com:443"} 0
+`
+	if body := w.Body.String(); !strings.Contains(body, expected) {
+		t.Errorf("metricsHandler() body = %s, expected to contain %s", body, expected)
+	}
+}
+
+func TestServer_intervalHandler(t *testing.T) {
+	var applied time.Duration
+	server := NewWithConfig(Config{
+		Port:      8080,
+		AuthToken: "secret",
+		SetInterval: func(interval time.Duration) {
+			applied = interval
+		},
+	})
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		expectedStatus int
+		expected       time.Duration
+	}{
+		{"valid interval", "PUT", "30s", http.StatusOK, 30 * time.Second},
+		{"too short", "PUT", "50ms", http.StatusBadRequest, 0},
+		{"too long", "PUT", "2h", http.StatusBadRequest, 0},
+		{"not a duration", "PUT", "often", http.StatusBadRequest, 0},
+		{"wrong method", "POST", "30s", http.StatusMethodNotAllowed, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applied = 0
+
+			req := httptest.NewRequest(tt.method, "/interval", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer secret")
+			w := httptest.NewRecorder()
+			server.server.Handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("%s /interval status = %d, expected %d", tt.method, w.Code, tt.expectedStatus)
+			}
+			if applied != tt.expected {
+				t.Errorf("Applied interval = %s, expected %s", applied, tt.expected)
+			}
+		})
+	}
+}
+
+func TestServer_intervalHandler_NoAuthToken(t *testing.T) {
+	called := false
+	server := NewWithConfig(Config{
+		Port: 8080,
+		SetInterval: func(time.Duration) {
+			called = true
+		},
+	})
+
+	req := httptest.NewRequest("PUT", "/interval", strings.NewReader("30s"))
+	w := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("PUT /interval status = %d, expected %d", w.Code, http.StatusNotFound)
+	}
+	if called {
+		t.Error("SetInterval called without an auth token configured")
+	}
+}
+
+func TestServer_maintenanceHandler_NoAuthToken(t *testing.T) {
+	called := false
+	server := NewWithConfig(Config{
+		Port: 8080,
+		SetMaintenance: func(bool) {
+			called = true
+		},
+	})
+
+	req := httptest.NewRequest("POST", "/maintenance", strings.NewReader("on"))
+	w := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("POST /maintenance status = %d, expected %d", w.Code, http.StatusNotFound)
+	}
+	if called {
+		t.Error("SetMaintenance called without an auth token configured")
+	}
+}
+
+func TestServer_maintenanceHandler(t *testing.T) {
+	var applied []bool
+	server := NewWithConfig(Config{
+		Port:      8080,
+		AuthToken: "secret",
+		SetMaintenance: func(enabled bool) {
+			applied = append(applied, enabled)
+		},
+	})
+	server.SetReady(true)
+
+	maintenance := func(body, token string) int {
+		req := httptest.NewRequest("POST", "/maintenance", strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		server.server.Handler.ServeHTTP(w, req)
+		return w.Code
+	}
+	ready := func() int {
+		w := httptest.NewRecorder()
+		server.readyHandler(w, httptest.NewRequest("GET", "/ready", nil))
+		return w.Code
+	}
+
+	// Requests without the token are rejected
+	if code := maintenance("on", ""); code != http.StatusUnauthorized {
+		t.Errorf("POST /maintenance without token status = %d, expected %d", code, http.StatusUnauthorized)
+	}
+	if code := maintenance("sometimes", "secret"); code != http.StatusBadRequest {
+		t.Errorf("POST /maintenance with invalid body status = %d, expected %d", code, http.StatusBadRequest)
+	}
+
+	// Entering maintenance reports not-ready
+	if code := maintenance("", "secret"); code != http.StatusOK {
+		t.Fatalf("POST /maintenance status = %d, expected %d", code, http.StatusOK)
+	}
+	if code := ready(); code != http.StatusServiceUnavailable {
+		t.Errorf("/ready in maintenance = %d, expected %d", code, http.StatusServiceUnavailable)
+	}
+	var metrics strings.Builder
+	server.WriteMetrics(&metrics)
+	if !strings.Contains(metrics.String(), "maintenance_mode 1\n") {
+		t.Errorf("WriteMetrics() = %q, expected maintenance_mode 1", metrics.String())
+	}
+
+	// Leaving maintenance restores readiness
+	if code := maintenance("off", "secret"); code != http.StatusOK {
+		t.Fatalf("POST /maintenance off status = %d, expected %d", code, http.StatusOK)
+	}
+	if code := ready(); code != http.StatusOK {
+		t.Errorf("/ready after maintenance = %d, expected %d", code, http.StatusOK)
+	}
+
+	if len(applied) != 2 || !applied[0] || applied[1] {
+		t.Errorf("Applied maintenance = %v, expected [true false]", applied)
+	}
+}
+
+func TestServer_readyHandler_RecentOutcomes(t *testing.T) {
+	server := NewWithConfig(Config{
+		Port:                      8080,
+		ReadinessWindow:           4,
+		ReadinessFailureThreshold: 0.5,
+	})
+	server.SetReady(true)
+
+	status := func() int {
+		req := httptest.NewRequest("GET", "/ready", nil)
+		w := httptest.NewRecorder()
+		server.readyHandler(w, req)
+		return w.Code
+	}
+
+	// Feed failures until the failure rate exceeds the threshold
+	server.RecordOutcome(true)
+	server.RecordOutcome(true)
+	server.RecordOutcome(false)
+	server.RecordOutcome(false)
+	if code := status(); code != http.StatusOK {
+		t.Errorf("readyHandler() status at threshold = %d, expected %d", code, http.StatusOK)
+	}
+	server.RecordOutcome(false)
+	if code := status(); code != http.StatusServiceUnavailable {
+		t.Errorf("readyHandler() status above threshold = %d, expected %d", code, http.StatusServiceUnavailable)
+	}
+
+	// Successes push the failures out of the window
+	server.RecordOutcome(true)
+	server.RecordOutcome(true)
+	if code := status(); code != http.StatusOK {
+		t.Errorf("readyHandler() status after recovery = %d, expected %d", code, http.StatusOK)
+	}
+
+	// SetReady(false) still overrides
+	server.SetReady(false)
+	if code := status(); code != http.StatusServiceUnavailable {
+		t.Errorf("readyHandler() status with SetReady(false) = %d, expected %d", code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestServer_readyHandler_Checks(t *testing.T) {
+	var targetErr error
+	server := NewWithConfig(Config{
+		Port: 8080,
+		ReadyChecks: []ReadyCheck{
+			{Name: "target", Check: func() error { return targetErr }},
+		},
+	})
+	server.SetReady(true)
+
+	ready := func() (int, map[string]string) {
+		req := httptest.NewRequest("GET", "/ready", nil)
+		w := httptest.NewRecorder()
+		server.readyHandler(w, req)
+
+		var body struct {
+			Checks map[string]string `json:"checks"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("Failed to parse /ready body %q: %v", w.Body.String(), err)
+		}
+		return w.Code, body.Checks
+	}
+
+	code, checks := ready()
+	if code != http.StatusOK {
+		t.Errorf("readyHandler() status with passing check = %d, expected %d", code, http.StatusOK)
+	}
+	if checks["target"] != "ok" {
+		t.Errorf("readyHandler() target check = %q, expected %q", checks["target"], "ok")
+	}
+
+	// A failing check reports not-ready with its message
+	targetErr = errors.New("connection refused")
+	code, checks = ready()
+	if code != http.StatusServiceUnavailable {
+		t.Errorf("readyHandler() status with failing check = %d, expected %d", code, http.StatusServiceUnavailable)
+	}
+	if checks["target"] != "connection refused" {
+		t.Errorf("readyHandler() target check = %q, expected %q", checks["target"], "connection refused")
+	}
+}
+
+func TestServer_metricsHandler_DurationPercentiles(t *testing.T) {
+	server := New(8080)
+
+	// Feed a uniform distribution of 1ms..1000ms
+	for i := 1; i <= 1000; i++ {
+		server.ObserveDuration(time.Duration(i) * time.Millisecond)
+	}
+
+	expected := map[string]float64{
+		"http_request_duration_p50_seconds": 0.50,
+		"http_request_duration_p95_seconds": 0.95,
+		"http_request_duration_p99_seconds": 0.99,
+	}
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	server.metricsHandler(w, req)
+
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		name, value, ok := strings.Cut(line, " ")
+		want, tracked := expected[name]
+		if !ok || !tracked {
+			continue
+		}
+		got, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			t.Fatalf("%s value %q is not a float: %v", name, value, err)
+		}
+		if math.Abs(got-want) > 0.01 {
+			t.Errorf("%s = %g, expected %g ± 0.01", name, got, want)
+		}
+		delete(expected, name)
+	}
+	for name := range expected {
+		t.Errorf("metricsHandler() body missing %s", name)
+	}
+}
+
+func TestServer_metricsHandler_InFlight(t *testing.T) {
+	server := New(8080)
+
+	// Start N concurrent slow requests
+	const n = 5
+	var started, release sync.WaitGroup
+	started.Add(n)
+	release.Add(1)
+	var done sync.WaitGroup
+	for i := 0; i < n; i++ {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			server.StartRequest()
+			started.Done()
+			release.Wait()
+			server.FinishRequest()
+		}()
+	}
+	started.Wait()
+
+	metrics := func() string {
+		req := httptest.NewRequest("GET", "/metrics", nil)
+		w := httptest.NewRecorder()
+		server.metricsHandler(w, req)
+		return w.Body.String()
+	}
+
+	body := metrics()
+	if !strings.Contains(body, "http_requests_in_flight 5\n") {
+		t.Errorf("metricsHandler() body = %s, expected http_requests_in_flight 5", body)
+	}
+
+	// Completing the requests lowers the gauge but keeps the high-water mark
+	release.Done()
+	done.Wait()
+
+	body = metrics()
+	if !strings.Contains(body, "http_requests_in_flight 0\n") {
+		t.Errorf("metricsHandler() body = %s, expected http_requests_in_flight 0", body)
+	}
+	if !strings.Contains(body, "http_requests_in_flight_max 5\n") {
+		t.Errorf("metricsHandler() body = %s, expected http_requests_in_flight_max 5", body)
+	}
+}
+
+func TestServer_Push(t *testing.T) {
+	// Create a stub Pushgateway that captures the push
+	var method, path, contentType, body string
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := io.ReadAll(r.Body)
+		method, path, contentType, body = r.Method, r.URL.Path, r.Header.Get("Content-Type"), string(payload)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer gateway.Close()
+
+	server := NewWithConfig(Config{
+		Port:           8080,
+		PushgatewayURL: gateway.URL,
+		PushJob:        "nightly",
+		PushInstance:   "runner-1",
+	})
+	server.IncrementRequests()
+
+	if err := server.Push(context.Background()); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	if method != http.MethodPut {
+		t.Errorf("Push() method = %s, expected PUT", method)
+	}
+	if path != "/metrics/job/nightly/instance/runner-1" {
+		t.Errorf("Push() path = %s, expected /metrics/job/nightly/instance/runner-1", path)
+	}
+	if !strings.HasPrefix(contentType, "text/plain") {
+		t.Errorf("Push() content type = %s, expected text/plain", contentType)
+	}
+	for _, name := range []string{"http_requests_total 1", "service_ready", "http_request_duration_p99_seconds"} {
+		if !strings.Contains(body, name) {
+			t.Errorf("Push() payload missing %q:\n%s", name, body)
+		}
+	}
+}
+
+func TestServer_Push_GroupingKeyEscaping(t *testing.T) {
+	tests := []struct {
+		name     string
+		job      string
+		instance string
+		expected string
+	}{
+		{"default job", "", "", "/metrics/job/tracer_test"},
+		{"space", "nightly run", "", "/metrics/job/nightly%20run"},
+		{"reserved characters", "a?b#c", "host:8080", "/metrics/job/a%3Fb%23c/instance/host:8080"},
+		{"slash in job", "team/nightly", "", "/metrics/job@base64/dGVhbS9uaWdodGx5"},
+		{"slash in instance", "nightly", "pod/runner-1", "/metrics/job/nightly/instance@base64/cG9kL3J1bm5lci0x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Capture the path as sent, before the server decodes it
+			var path string
+			gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.EscapedPath()
+			}))
+			defer gateway.Close()
+
+			server := NewWithConfig(Config{
+				Port:           8080,
+				PushgatewayURL: gateway.URL + "/",
+				PushJob:        tt.job,
+				PushInstance:   tt.instance,
+			})
+			if err := server.Push(context.Background()); err != nil {
+				t.Fatalf("Push() error = %v", err)
+			}
+			if path != tt.expected {
+				t.Errorf("Push() path = %s, expected %s", path, tt.expected)
+			}
+		})
+	}
+}
+
+func TestServer_Push_GatewayStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected string
+	}{
+		{"ok", http.StatusOK, "", ""},
+		{"accepted", http.StatusAccepted, "", ""},
+		{"bad request", http.StatusBadRequest, "pushed metrics are invalid or inconsistent\n", "HTTP 400: pushed metrics are invalid or inconsistent"},
+		{"server error", http.StatusInternalServerError, "", "HTTP 500"},
+		{"long body", http.StatusBadRequest, strings.Repeat("x", 2*maxPushErrorBody), "HTTP 400: " + strings.Repeat("x", maxPushErrorBody)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = io.WriteString(w, tt.body)
+			}))
+			defer gateway.Close()
+
+			server := NewWithConfig(Config{Port: 8080, PushgatewayURL: gateway.URL})
+			err := server.Push(context.Background())
+			if tt.expected == "" {
+				if err != nil {
+					t.Errorf("Push() error = %v, expected nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Push() error = nil, expected an error")
+			}
+			if !strings.HasSuffix(err.Error(), tt.expected) {
+				t.Errorf("Push() error = %q, expected it to end with %q", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestServer_Push_GatewayHung(t *testing.T) {
+	release := make(chan struct{})
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer gateway.Close()
+	defer close(release)
+
+	// Shorten the push timeout for the test
+	previous := pushClient
+	pushClient = &http.Client{Timeout: 50 * time.Millisecond}
+	defer func() { pushClient = previous }()
+
+	server := NewWithConfig(Config{Port: 8080, PushgatewayURL: gateway.URL})
+	start := time.Now()
+	if err := server.Push(context.Background()); err == nil {
+		t.Error("Push() error = nil, expected a timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Push() took %s, expected to give up after the push timeout", elapsed)
+	}
+}
@@ -0,0 +1,56 @@
+package health
+
+import (
+	"strconv"
+	"sync"
+)
+
+// sizeBuckets are the upper bounds in bytes used for payload size histograms
+var sizeBuckets = []float64{100, 1000, 10000, 100000, 1000000, 10000000}
+
+// durationBuckets are the upper bounds in seconds used for latency histograms
+var durationBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+// histogram is a cumulative histogram rendered in Prometheus text format
+type histogram struct {
+	mu      sync.Mutex
+	bounds  []float64
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+// newHistogram creates a histogram with the given bucket upper bounds
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{
+		bounds:  bounds,
+		buckets: make([]uint64, len(bounds)),
+	}
+}
+
+// Observe records a single value
+func (h *histogram) Observe(value float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, bound := range h.bounds {
+		if value <= bound {
+			h.buckets[i]++
+		}
+	}
+	h.count++
+	h.sum += value
+}
+
+// write renders the histogram series for name to w
+func (h *histogram) write(m *metricWriter, name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, bound := range h.bounds {
+		m.intSample(name+"_bucket", int64(h.buckets[i]), label{"le", strconv.FormatFloat(bound, 'g', -1, 64)})
+	}
+	m.intSample(name+"_bucket", int64(h.count), label{"le", "+Inf"})
+	m.floatSample(name+"_sum", h.sum)
+	m.intSample(name+"_count", int64(h.count))
+}
@@ -0,0 +1,25 @@
+package health
+
+import "sync/atomic"
+
+// inFlight tracks the number of requests in progress and its high-water mark
+type inFlight struct {
+	current atomic.Int64
+	max     atomic.Int64
+}
+
+// start counts a request as in flight, raising the high-water mark if needed
+func (f *inFlight) start() {
+	n := f.current.Add(1)
+	for {
+		max := f.max.Load()
+		if n <= max || f.max.CompareAndSwap(max, n) {
+			return
+		}
+	}
+}
+
+// finish counts a request as completed
+func (f *inFlight) finish() {
+	f.current.Add(-1)
+}
@@ -0,0 +1,66 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+
+	"go.opentelemetry.io/otel"
+	sdk "go.opentelemetry.io/otel/sdk"
+)
+
+// BuildInfo describes the running build, as set at link time
+type BuildInfo struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit,omitempty"`
+	Date    string `json:"date,omitempty"`
+}
+
+// info is the body served by /info
+type info struct {
+	BuildInfo
+	GoVersion    string            `json:"go_version"`
+	Dependencies map[string]string `json:"dependencies"`
+}
+
+// infoModules lists the dependencies whose versions /info reports, read
+// from the binary's build information
+var infoModules = []string{
+	"go.uber.org/zap",
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp",
+}
+
+// dependencyVersions returns the versions of key dependencies. The OTel
+// versions come from the modules themselves, the rest from build
+// information when the binary carries it.
+func dependencyVersions() map[string]string {
+	versions := map[string]string{
+		"go.opentelemetry.io/otel":     otel.Version(),
+		"go.opentelemetry.io/otel/sdk": sdk.Version(),
+	}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return versions
+	}
+	for _, dep := range build.Deps {
+		for _, path := range infoModules {
+			if dep.Path == path {
+				versions[path] = dep.Version
+			}
+		}
+	}
+	return versions
+}
+
+// infoHandler handles /info, reporting the build, Go runtime and dependency
+// versions
+func (s *Server) infoHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(info{
+		BuildInfo:    s.config.BuildInfo,
+		GoVersion:    runtime.Version(),
+		Dependencies: dependencyVersions(),
+	})
+}
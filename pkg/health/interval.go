@@ -0,0 +1,58 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Bounds accepted by PUT /interval
+const (
+	minInterval = 100 * time.Millisecond
+	maxInterval = time.Hour
+)
+
+// IntervalFunc applies a new request interval
+type IntervalFunc func(interval time.Duration)
+
+// intervalHandler handles PUT /interval. The body is a Go duration such as "30s".
+func (s *Server) intervalHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		w.Header().Set("Allow", http.MethodPut)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	interval, err := parseInterval(strings.TrimSpace(string(body)))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.config.SetInterval(interval)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]string{"interval": interval.String()})
+}
+
+// parseInterval parses and bounds-checks a request interval
+func parseInterval(value string) (time.Duration, error) {
+	interval, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid interval %q", value)
+	}
+	if interval < minInterval || interval > maxInterval {
+		return 0, fmt.Errorf("interval must be between %s and %s", minInterval, maxInterval)
+	}
+	return interval, nil
+}
@@ -0,0 +1,86 @@
+package health
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// seriesLabels are the label names set by the metrics themselves, which
+// constant labels must not reuse
+var seriesLabels = map[string]bool{"le": true, "host": true}
+
+// ValidateLabelName reports whether name can be used as a constant label.
+// Names starting with "__" are reserved by Prometheus, and names the
+// metrics already use would produce duplicate labels.
+func ValidateLabelName(name string) error {
+	if strings.HasPrefix(name, "__") {
+		return fmt.Errorf("label %q is reserved, names starting with __ are for internal use", name)
+	}
+	if seriesLabels[name] {
+		return fmt.Errorf("label %q is already used by the metrics", name)
+	}
+	return nil
+}
+
+// labelValueEscaper escapes label values for the text exposition format
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// label is a single name="value" pair on a series
+type label struct {
+	name, value string
+}
+
+// metricWriter writes samples in the text exposition format, giving every
+// series the constant labels ahead of its own
+type metricWriter struct {
+	w      io.Writer
+	labels []label
+}
+
+// newMetricWriter creates a metricWriter adding constLabels, sorted by
+// name, to every series written to w
+func newMetricWriter(w io.Writer, constLabels map[string]string) *metricWriter {
+	labels := make([]label, 0, len(constLabels))
+	for name, value := range constLabels {
+		labels = append(labels, label{name, value})
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].name < labels[j].name })
+	return &metricWriter{w: w, labels: labels}
+}
+
+// comment writes a # comment line
+func (m *metricWriter) comment(text string) {
+	_, _ = io.WriteString(m.w, "# "+text+"\n")
+}
+
+// intSample writes an integer sample
+func (m *metricWriter) intSample(name string, value int64, labels ...label) {
+	m.sample(name, strconv.FormatInt(value, 10), labels)
+}
+
+// floatSample writes a floating point sample
+func (m *metricWriter) floatSample(name string, value float64, labels ...label) {
+	m.sample(name, strconv.FormatFloat(value, 'g', -1, 64), labels)
+}
+
+// sample writes one series line with the constant labels followed by labels
+func (m *metricWriter) sample(name, value string, labels []label) {
+	var b strings.Builder
+	b.WriteString(name)
+	if len(m.labels)+len(labels) > 0 {
+		b.WriteByte('{')
+		for i, l := range slices.Concat(m.labels, labels) {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(l.name + `="` + labelValueEscaper.Replace(l.value) + `"`)
+		}
+		b.WriteByte('}')
+	}
+	b.WriteString(" " + value + "\n")
+	_, _ = io.WriteString(m.w, b.String())
+}
@@ -0,0 +1,62 @@
+package health
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// MaintenanceFunc pauses (true) or resumes (false) the request loop
+type MaintenanceFunc func(enabled bool)
+
+// maintenanceHandler handles POST /maintenance. The body is "on" (the
+// default when empty) to pause requests and report not-ready, or "off" to
+// resume.
+func (s *Server) maintenanceHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	var enabled bool
+	switch strings.ToLower(strings.TrimSpace(string(body))) {
+	case "", "on":
+		enabled = true
+	case "off":
+		enabled = false
+	default:
+		http.Error(w, `body must be "on" or "off"`, http.StatusBadRequest)
+		return
+	}
+
+	s.SetMaintenance(enabled)
+	s.config.SetMaintenance(enabled)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]bool{"maintenance": enabled})
+}
+
+// SetMaintenance sets whether the prober is in maintenance. /ready reports
+// not-ready while it is.
+func (s *Server) SetMaintenance(enabled bool) {
+	if enabled {
+		atomic.StoreInt32(&s.maintenance, 1)
+	} else {
+		atomic.StoreInt32(&s.maintenance, 0)
+	}
+}
+
+// InMaintenance reports whether the prober is in maintenance
+func (s *Server) InMaintenance() bool {
+	return atomic.LoadInt32(&s.maintenance) == 1
+}
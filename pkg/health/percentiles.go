@@ -0,0 +1,52 @@
+package health
+
+import (
+	"sync"
+
+	"github.com/influxdata/tdigest"
+)
+
+// reportedQuantiles are the percentiles exposed on /metrics
+var reportedQuantiles = []struct {
+	name     string
+	quantile float64
+}{
+	{"p50", 0.50},
+	{"p95", 0.95},
+	{"p99", 0.99},
+}
+
+// percentiles estimates quantiles of observed values with a t-digest
+type percentiles struct {
+	mu     sync.Mutex
+	digest *tdigest.TDigest
+}
+
+// newPercentiles creates an empty percentile estimator
+func newPercentiles() *percentiles {
+	return &percentiles{digest: tdigest.NewWithCompression(100)}
+}
+
+// Observe records a value
+func (p *percentiles) Observe(value float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.digest.Add(value, 1)
+}
+
+// Quantile returns the estimated value at q (0-1), or 0 with no observations
+func (p *percentiles) Quantile(q float64) float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.digest.Count() == 0 {
+		return 0
+	}
+	return p.digest.Quantile(q)
+}
+
+// write emits one <prefix>_<pNN>_<suffix> line per reported quantile
+func (p *percentiles) write(m *metricWriter, prefix, suffix string) {
+	for _, rq := range reportedQuantiles {
+		m.floatSample(prefix+"_"+rq.name+"_"+suffix, p.Quantile(rq.quantile))
+	}
+}
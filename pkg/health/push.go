@@ -0,0 +1,108 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// defaultPushJob is the job label used when PushJob is empty
+const defaultPushJob = "tracer_test"
+
+// pushTimeout bounds each push so a hung Pushgateway cannot block shutdown
+const pushTimeout = 10 * time.Second
+
+// maxPushErrorBody caps how much of a failed push's response is reported
+const maxPushErrorBody = 512
+
+// pushClient sends metrics to the Pushgateway. The metrics are rendered as
+// exposition text rather than kept in a client_golang registry, so they are
+// PUT directly instead of going through client_golang's push package.
+var pushClient = &http.Client{Timeout: pushTimeout}
+
+// Push sends the current metrics to the configured Pushgateway, replacing
+// any metrics previously pushed for the same job and instance
+func (s *Server) Push(ctx context.Context) error {
+	if s.config.PushgatewayURL == "" {
+		return nil
+	}
+
+	var body bytes.Buffer
+	s.WriteMetrics(&body)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.pushURL(), &body)
+	if err != nil {
+		return fmt.Errorf("failed to create push request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	resp, err := pushClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push metrics: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// The Pushgateway explains rejected payloads in the body
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, maxPushErrorBody))
+		if detail := strings.TrimSpace(string(message)); detail != "" {
+			return fmt.Errorf("failed to push metrics: pushgateway returned HTTP %d: %s", resp.StatusCode, detail)
+		}
+		return fmt.Errorf("failed to push metrics: pushgateway returned HTTP %d", resp.StatusCode)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// RunPush pushes metrics every PushInterval until ctx is done, reporting
+// failures to onError. It returns immediately when periodic pushing is not
+// configured.
+func (s *Server) RunPush(ctx context.Context, onError func(error)) {
+	if s.config.PushgatewayURL == "" || s.config.PushInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.PushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Push(ctx); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// pushURL returns the Pushgateway grouping URL for the job and instance
+func (s *Server) pushURL() string {
+	job := s.config.PushJob
+	if job == "" {
+		job = defaultPushJob
+	}
+
+	target := strings.TrimSuffix(s.config.PushgatewayURL, "/") + "/metrics" + groupingLabel("job", job)
+	if s.config.PushInstance != "" {
+		target += groupingLabel("instance", s.config.PushInstance)
+	}
+	return target
+}
+
+// groupingLabel returns the grouping key path segment for one label. Values
+// containing a slash use the Pushgateway's base64 form, since an escaped
+// %2F may be decoded back into a path separator along the way.
+func groupingLabel(name, value string) string {
+	if strings.Contains(value, "/") {
+		return "/" + name + "@base64/" + base64.RawURLEncoding.EncodeToString([]byte(value))
+	}
+	return "/" + name + "/" + url.PathEscape(value)
+}
@@ -0,0 +1,48 @@
+package health
+
+import "sync"
+
+// outcomeWindow tracks the success of the last N requests
+type outcomeWindow struct {
+	mu       sync.Mutex
+	outcomes []bool
+	next     int
+	count    int
+	failures int
+}
+
+// newOutcomeWindow creates a window over the last size outcomes
+func newOutcomeWindow(size int) *outcomeWindow {
+	return &outcomeWindow{outcomes: make([]bool, size)}
+}
+
+// record adds an outcome, evicting the oldest once the window is full
+func (w *outcomeWindow) record(success bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.count == len(w.outcomes) {
+		if !w.outcomes[w.next] {
+			w.failures--
+		}
+	} else {
+		w.count++
+	}
+
+	w.outcomes[w.next] = success
+	if !success {
+		w.failures++
+	}
+	w.next = (w.next + 1) % len(w.outcomes)
+}
+
+// failureRate returns the fraction of failed outcomes in the window
+func (w *outcomeWindow) failureRate() float64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.count == 0 {
+		return 0
+	}
+	return float64(w.failures) / float64(w.count)
+}
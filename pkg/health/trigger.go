@@ -0,0 +1,145 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// maxTriggerBodySize bounds the JSON body accepted by POST /trigger
+const maxTriggerBodySize = 64 * 1024
+
+// triggerMethods are the methods a /trigger override may use
+var triggerMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodHead:   true,
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodDelete: true,
+}
+
+// TriggerResult describes the outcome of an on-demand request
+type TriggerResult struct {
+	StatusCode int    `json:"status_code,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	TraceID    string `json:"trace_id"`
+	Error      string `json:"error,omitempty"`
+	// ResponseSnippet is the start of the response body, for overrides
+	ResponseSnippet string `json:"response_snippet,omitempty"`
+}
+
+// TriggerRequest overrides the configured request for one /trigger call
+type TriggerRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+}
+
+// TriggerFunc performs a single traced request and reports its outcome.
+// override is nil when the configured request should be sent.
+type TriggerFunc func(ctx context.Context, override *TriggerRequest) TriggerResult
+
+// requestIDKey is the context key for the caller's X-Request-ID
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the X-Request-ID sent by the /trigger caller,
+// if any
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// triggerHandler handles POST /trigger. An optional JSON TriggerRequest body
+// overrides the method, URL and headers; overrides require AuthToken.
+// A traceparent header makes the dispatched request part of the caller's
+// trace, and X-Request-ID is available through RequestIDFromContext.
+func (s *Server) triggerHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	override, err := parseTriggerRequest(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if override != nil && s.config.AuthToken == "" {
+		http.Error(w, "request overrides require an admin token", http.StatusForbidden)
+		return
+	}
+
+	// Continue the caller's trace and request ID
+	ctx := propagation.TraceContext{}.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	if id := r.Header.Get("X-Request-ID"); id != "" {
+		ctx = context.WithValue(ctx, requestIDKey{}, id)
+	}
+
+	result := s.config.Trigger(ctx, override)
+	s.IncrementRequests()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// requireAuth rejects requests without the configured bearer token
+func (s *Server) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.config.AuthToken != "" {
+			expected := "Bearer " + s.config.AuthToken
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(expected)) != 1 {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// parseTriggerRequest decodes and validates an optional /trigger body,
+// returning nil when the body is empty
+func parseTriggerRequest(body io.Reader) (*TriggerRequest, error) {
+	data, err := io.ReadAll(io.LimitReader(body, maxTriggerBodySize+1))
+	if err != nil {
+		return nil, errors.New("failed to read body")
+	}
+	if len(data) > maxTriggerBodySize {
+		return nil, fmt.Errorf("body exceeds %d bytes", maxTriggerBodySize)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var req TriggerRequest
+	if err := decoder.Decode(&req); err != nil {
+		return nil, fmt.Errorf("invalid trigger request: %w", err)
+	}
+
+	req.Method = strings.ToUpper(req.Method)
+	if req.Method == "" {
+		req.Method = http.MethodGet
+	}
+	if !triggerMethods[req.Method] {
+		return nil, fmt.Errorf("unsupported method %q", req.Method)
+	}
+
+	target, err := url.Parse(req.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, fmt.Errorf("invalid url %q, expected an absolute http or https URL", req.URL)
+	}
+
+	return &req, nil
+}
@@ -15,36 +15,360 @@ OPTIONS:
     -url string
         URL to make GET request to (default: "https://httpbin.org/get")
     
+    -url-stdin
+        Read the target URL from stdin instead of -url, e.g.
+        generate-url | tracer-test -url-stdin. Empty lines and # comments
+        are skipped; exactly one URL must remain
+    
     -otlp-endpoint string
         OTLP endpoint for traces (default: "http://localhost:4318")
         Examples:
             - http://localhost:4318 (local OTLP collector)
             - https://your-otlp-endpoint.com (external OTLP collector)
             - alloy-test.cel2.celo-networks-dev.org (external domain, auto-detects HTTPS)
+            - http://localhost:4318,https://central.example.com (export to both)
+    
+    -otlp-ca-cert string
+        PEM file of CA certificates to trust for https OTLP endpoints, for
+        collectors behind a private CA. Verification stays enabled
+        (default: system roots)
     
     -service-name string
         Service name for tracing (default: "http-client")
     
+    -environment string
+        Deployment environment recorded as deployment.environment on spans
+        and logs (default: $DEPLOYMENT_ENVIRONMENT, omitted when empty)
+    
+    -region string
+        Cloud region recorded as cloud.region on spans and logs (default:
+        $CLOUD_REGION, omitted when empty)
+    
+    -synthetic
+        Flag root spans (synthetic=true, user_agent.synthetic.type=test) and
+        the resource (synthetic=true) as synthetic monitoring traffic so
+        backends can keep it out of real RED metrics
+    
+    -include-vcs-info
+        Record the vcs.revision, vcs.time and vcs.modified settings embedded
+        by go build as resource attributes, when the binary carries them
+    
+    -propagators string
+        Comma-separated trace context formats sent to the target, for
+        services that don't speak W3C traceparent (default:
+        "tracecontext,baggage")
+        Options: tracecontext, baggage, b3 (single header), b3multi
+        (X-B3-* headers), jaeger (uber-trace-id)
+    
     -interval duration
         Interval between requests (default: "5s")
         Examples: "1s", "30s", "1m", "2h30m"
     
+    -schedule string
+        Cron expression to run requests on instead of -interval, e.g.
+        "*/5 9-17 * * 1-5" for every 5 minutes during weekday business hours.
+        An optional leading seconds field and descriptors such as @hourly are
+        accepted. Cannot be combined with -interval or -adaptive-interval
+    
     -log-level string
         Log level (default: "info")
         Options: debug, info, warn, error
     
     -log-format string
         Log format (default: "json")
-        Options: json, console
+        Options: json, console, logfmt
+    
+    -log-field-names string
+        Comma-separated default=custom pairs renaming the timestamp, level,
+        msg and caller keys of json and logfmt logs
+        (e.g. "level=severity,timestamp=time")
+    
+    -log-buffer-size int
+        Buffer up to this many bytes of log output before writing it, for
+        high-throughput logging. Buffered logs are flushed on shutdown
+        (default: 0, unbuffered)
+    
+    -log-flush-interval duration
+        With -log-buffer-size, flush buffered logs at least this often
+        (default: 30s)
+    
+    -trace-url-template string
+        Template of a link to each cycle's trace in the tracing UI, logged
+        as the trace_url field, e.g.
+        https://jaeger.example.com/trace/{{.TraceID}} ({{.SpanID}} is also
+        available)
     
     -disable-otlp
         Disable OTLP tracing export (useful for testing without backend)
     
+    -trace-file string
+        Write spans as JSON lines to this file instead of exporting via OTLP
+        The file is appended to and never rotated or truncated
+    
+    -export-on-error-only
+        Buffer the spans of each request cycle and only export cycles that
+        failed (or were slow, see -slow-cycle-threshold). Trades memory for volume
+    
+    -slow-cycle-threshold duration
+        With -export-on-error-only, also export cycles at least this slow (default: disabled)
+    
+    -min-span-duration duration
+        Only export the spans of cycles whose request.cycle span lasted longer
+        than this, failed or not, to focus traces on slow requests. With
+        -export-on-error-only, a cycle must meet both conditions
+        (default: disabled)
+    
+    -otlp-queue-size int
+        Spans queued per exporter before new spans are dropped. Drops are
+        reported as otlp_spans_dropped_total on /metrics (default: 2048)
+    
+    -otlp-block-on-queue-full
+        Wait for room in a full export queue instead of dropping spans. This
+        applies backpressure: requests slow down while the exporter is slow
+    
+    -otlp-export-duration
+        Time every span export and expose it as the
+        otlp_export_duration_seconds histogram on /metrics, for
+        troubleshooting a slow collector
+    
+    -sample-ratio float
+        Fraction (0-1) of request cycles to trace. Each cycle logs whether it
+        was sampled, counted as traces_sampled_total and traces_dropped_total
+        on /metrics (default: 1)
+    
+    -detail-span-sample-ratio float
+        Fraction (0-1) of requests that get the detailed http.transport,
+        dns.resolve and tcp.connect spans. request.cycle and http.get spans
+        are always kept, cutting volume without losing coverage (default: 1)
+    
+    -max-spans-per-cycle int
+        Stop exporting child spans once a request cycle has this many, adding
+        a span_limit_reached event to its root span instead, to protect the
+        collector from pathological cycles. The target is still told to
+        sample, so its spans may reference unexported parents
+        (default: 0, unlimited)
+    
+    -accept string
+        Accept header to send with each request (default: let the server choose)
+        Example: "application/json"
+    
+    -non-error-status-codes string
+        Comma-separated status codes >= 400 that are expected and not treated
+        as errors (e.g. "404,410" for cache-miss probes)
+    
+    -expected-status-codes string
+        Comma-separated status codes treated as success (e.g. "200"). Any
+        other code, even a 301 or 204, fails the request and is recorded as
+        http.unexpected_status=true. Overrides -non-error-status-codes
+        (default: any code < 400)
+    
+    -probe string
+        Probe mode (default: "http")
+        Options: http, tcp, dns
+        tcp only dials the -url host and port and records a tcp.probe span
+        with the connect latency, skipping the HTTP exchange
+        dns only resolves the -url hostname and records a dns.probe span
+        with the resolution time and returned addresses
+    
+    -statsd-addr string
+        StatsD host:port to send request count, error count and duration
+        timings to over UDP after each request (default: disabled)
+    
+    -enable-trigger
+        Expose POST /trigger on the health server to fire a single traced
+        request on demand. Returns status, duration and trace_id as JSON.
+        With -admin-token, a JSON body {"method","url","headers"} overrides
+        the configured request for that call. The caller's traceparent and
+        X-Request-ID headers are carried over to the dispatched request
+    
+    -admin-token string
+        Bearer token required by administrative health endpoints (e.g. /trigger,
+        /interval and /maintenance)
+    
+    -health-methods string
+        Comma-separated HTTP methods accepted by /health, /ready, /metrics and
+        /info, e.g. "GET,HEAD,POST" for probing systems that POST. Others get
+        405 Method Not Allowed (default: "GET,HEAD")
+    
+    -ready-requires-export
+        Report not-ready on /ready while trace export is failing
+    
+    -ready-checks-target
+        Report not-ready on /ready until the -url target has responded, and
+        while its latest request got no response at all (any status counts
+        as reachable). /ready lists the result under "checks"
+    
+    -ready-window int
+        Derive readiness from the last N request outcomes (default: disabled)
+    
+    -ready-failure-threshold float
+        With -ready-window, report not-ready on /ready when the recent failure
+        rate exceeds this fraction (default: 0.5)
+    
+    -ready-delay duration
+        Report not-ready on /ready for this long after startup so dependencies
+        such as the collector connection can warm up (default: 0s)
+    
+    -capture-response-headers string
+        Comma-separated response headers (e.g. X-Trace-Context) to record on
+        spans and carry as W3C baggage on later requests
+    
+    -dns-cache-ttl duration
+        Cache successful DNS resolutions in process for this long; dns.resolve
+        spans are marked dns.cache=hit or miss (default: disabled)
+    
+    -max-concurrent-dns int
+        Maximum DNS lookups in flight at once, including those made when
+        dialing, so bursts of requests don't overwhelm the resolver. Lookups
+        that queue record dns.queue_wait_ms (default: 0, unlimited)
+    
+    -dial-timeout duration
+        Time allowed to establish each TCP connection, recorded as
+        net.dial.timeout_ms. Lower it to fail fast on unreachable hosts
+        (default: 30s)
+    
+    -tcp-keep-alive duration
+        TCP keep-alive period for connections; negative disables keep-alive
+        probes (default: 30s)
+    
+    -socket-read-timeout duration
+        Time allowed for each individual socket read, detecting connections
+        that hang mid-transfer. Failures are recorded as
+        error.category=socket_timeout. Idle keep-alive connections are
+        closed after this long (default: 0, disabled)
+    
+    -socket-write-timeout duration
+        Time allowed for each individual socket write. Failures are recorded
+        as error.category=socket_timeout (default: 0, disabled)
+    
+    -max-retries int
+        Retry requests that fail with a network error or a 5xx status up to
+        this many times. Each attempt is an http.get.attempt span with
+        http.retry.count; the cycle only fails if the last attempt does.
+        Running out of retries is logged as retries_exhausted and counted as
+        http_retries_exhausted_total (default: 0, no retries)
+    
+    -retry-backoff duration
+        With -max-retries, the backoff before the first retry, doubled for
+        each further retry with jitter up to 30s (default: 100ms)
+    
+    -scrub-query-params string
+        Comma-separated query parameters (e.g. token,api_key) whose values are
+        replaced with REDACTED in span attributes and logs. The real URL is
+        still sent. Use "*" to drop the whole query
+    
+    -min-tls-version string
+        Minimum TLS version: 1.0, 1.1, 1.2 or 1.3 (default: Go's default)
+    
+    -cipher-suites string
+        Comma-separated allowed cipher suites for TLS 1.0-1.2, using Go names
+        (e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256). Invalid names fail startup
+    
+    -peer-services string
+        Comma-separated service=url pairs naming the service behind each
+        target URL, recorded as peer.service on its spans
+    
+    -adaptive-interval
+        Double the interval after each failed request, up to -max-interval,
+        and reset it to -interval on the first success
+    
+    -max-interval duration
+        With -adaptive-interval, the longest the interval may back off to
+        (default: 5m)
+    
+    -ramp-up duration
+        Start at -ramp-start-interval and shorten the interval linearly to
+        -interval over this long, easing into load. The current rate is
+        reported as request_rate_per_second on /metrics (default: disabled)
+    
+    -ramp-start-interval duration
+        With -ramp-up, the interval to start from (default: 10x -interval)
+    
+    -count int
+        Stop after this many requests (default: 0, unlimited)
+    
+    -max-runtime duration
+        Stop after running this long (default: 0s, unlimited)
+    
+    -fail-fast
+        Stop on the first failed request or unexpected status and exit with
+        status 1 after flushing spans, for CI smoke tests
+    
+    -benchmark
+        When the run ends, print a table with requests/sec, error rate,
+        bytes/sec and min/avg/p50/p95/p99/max latency to stdout. Combine with
+        -count or -max-runtime and a short -interval. Logs go to stderr
+    
+    -manifest-file string
+        When the run ends, write a JSON manifest with the configuration
+        (tokens redacted), build info, start and end times, total requests
+        and aggregate stats to this file, for CI artifacts
+    
+    -max-bytes int
+        Stop cleanly once this many response body bytes have been downloaded,
+        finishing the in-flight request, for cost-controlled runs against
+        metered APIs (default: 0, unlimited)
+    
+    -pushgateway-url string
+        Prometheus Pushgateway URL to push the /metrics data to on shutdown,
+        for runs that can't be scraped (default: disabled)
+    
+    -push-interval duration
+        With -pushgateway-url, also push metrics this often (default: only
+        on shutdown)
+    
+    -push-job string
+        With -pushgateway-url, the job label (default: the service name)
+    
+    -push-instance string
+        With -pushgateway-url, the instance label (default: none)
+    
+    -metrics-labels string
+        Comma-separated name=value constant labels added to every /metrics
+        series, for multi-tenant dashboards
+        (e.g. "service=api,environment=prod,instance=pod-1"). Names starting
+        with __ and the series labels le and host are rejected
+    
+    -bearer-token-file string
+        File containing a bearer token sent as the Authorization header. The
+        file is reread every 10s so rotated tokens are picked up
+    
+    -failure-injection-rate float
+        Probability (0-1) of failing a request with a synthetic error instead
+        of sending it, for chaos testing (default: 0)
+    
+    -injected-latency duration
+        Delay every request by this long before sending it, recorded as
+        http.injected_latency_ms, for testing latency alerting (default: 0s)
+    
+    -injected-latency-jitter duration
+        Add a random delay up to this long to every request, on top of
+        -injected-latency (default: 0s)
+    
+    -curl
+        Make a single traced GET request to -url, print the status line,
+        headers and body to stdout like curl -i, and exit. Logs go to stderr
+    
+    -check-cert
+        Connect to each https OTLP endpoint (or -url with -disable-otlp),
+        print the certificate chain's subject, issuer, validity and SANs, and
+        exit. Exits with status 1 if a certificate is expired or not yet
+        valid, or the TLS handshake fails
+    
+    -emit-trace-ids
+        Print "TRACE <trace_id> <url> <status>" to stdout after each request
+        cycle, separate from the structured logs, for linking to traces in CI
+    
     -help
         Show this help message and exit
     
     -version
         Show version information and exit
+    
+    -list-flags
+        Print every flag's name, type, default, current value and usage as
+        a JSON array and exit, for tools that wrap this binary. Token values
+        are redacted
 
 EXAMPLES:
     # Basic usage with default settings
@@ -59,6 +383,9 @@ EXAMPLES:
     # Disable OTLP tracing for testing
     tracer-test -disable-otlp -log-format console
 
+    # Check TCP reachability of a host without an HTTP exchange
+    tracer-test -url "https://api.example.com" -probe tcp
+
     # High-frequency requests with custom service name
     tracer-test -url "https://httpbin.org/json" -interval 1s -service-name "load-tester"
 
@@ -87,6 +414,12 @@ HEALTH CHECKS:
     • GET /health - Basic health check
     • GET /ready - Readiness check
     • GET /metrics - Simple metrics endpoint
+    • GET /info - Build, Go runtime and dependency versions
+    • POST /trigger - Fire a single request on demand (requires -enable-trigger)
+    • PUT /interval - Change the request interval at runtime (body e.g. "30s",
+      between 100ms and 1h; requires -admin-token)
+    • POST /maintenance - Pause requests and report not-ready (body "on",
+      the default, or "off" to resume; requires -admin-token)
 
 `)
 }
@@ -0,0 +1,69 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// baggageCapture records configured response headers on spans and carries
+// their latest values as W3C baggage on subsequent requests
+type baggageCapture struct {
+	headers []string
+
+	mu      sync.Mutex
+	members map[string]string
+}
+
+// newBaggageCapture creates a capture for the given response header names
+func newBaggageCapture(headers []string) *baggageCapture {
+	return &baggageCapture{
+		headers: headers,
+		members: make(map[string]string),
+	}
+}
+
+// capture records the configured headers present in resp on span
+func (b *baggageCapture) capture(span trace.Span, header http.Header) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, name := range b.headers {
+		value := header.Get(name)
+		if value == "" {
+			continue
+		}
+		key := strings.ToLower(name)
+		span.SetAttributes(attribute.String("http.response.header."+key, value))
+		b.members[key] = value
+	}
+}
+
+// inject adds the captured values to the baggage in ctx and writes the
+// result as the baggage header on req
+func (b *baggageCapture) inject(ctx context.Context, req *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.members) == 0 {
+		return
+	}
+
+	bag := baggage.FromContext(ctx)
+	for key, value := range b.members {
+		member, err := baggage.NewMemberRaw(key, value)
+		if err != nil {
+			continue
+		}
+		if updated, err := bag.SetMember(member); err == nil {
+			bag = updated
+		}
+	}
+	propagation.Baggage{}.Inject(baggage.ContextWithBaggage(ctx, bag), propagation.HeaderCarrier(req.Header))
+}
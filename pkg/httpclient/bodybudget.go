@@ -0,0 +1,65 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"tracer-test/pkg/clock"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// ErrBodyMemoryExhausted is returned by ReadBody when the body memory budget
+// is exhausted and BodyMemoryFailFast is set
+var ErrBodyMemoryExhausted = errors.New("response body memory budget exhausted")
+
+// bodyBudget bounds the memory held by response bodies being read across all
+// requests of a client. Each read reserves its Content-Length up front;
+// bodies of unknown length reserve the whole budget.
+type bodyBudget struct {
+	sem      *semaphore.Weighted
+	total    int64
+	failFast bool
+	clock    clock.Clock
+}
+
+// newBodyBudget creates a budget of total bytes, or returns nil when total
+// is not positive
+func newBodyBudget(total int64, failFast bool, clk clock.Clock) *bodyBudget {
+	if total <= 0 {
+		return nil
+	}
+	return &bodyBudget{
+		sem:      semaphore.NewWeighted(total),
+		total:    total,
+		failFast: failFast,
+		clock:    clk,
+	}
+}
+
+// acquire reserves the estimated body size of resp, blocking until it is
+// available unless failFast is set. It returns a func releasing the
+// reservation and how long the caller waited.
+func (b *bodyBudget) acquire(ctx context.Context, resp *http.Response) (func(), time.Duration, error) {
+	n := resp.ContentLength
+	if n < 0 || n > b.total {
+		n = b.total
+	}
+	release := func() { b.sem.Release(n) }
+
+	if b.failFast {
+		if !b.sem.TryAcquire(n) {
+			return nil, 0, fmt.Errorf("%w: %d bytes needed", ErrBodyMemoryExhausted, n)
+		}
+		return release, 0, nil
+	}
+
+	start := b.clock.Now()
+	if err := b.sem.Acquire(ctx, n); err != nil {
+		return nil, b.clock.Now().Sub(start), fmt.Errorf("waiting for body memory: %w", err)
+	}
+	return release, b.clock.Now().Sub(start), nil
+}
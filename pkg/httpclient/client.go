@@ -1,14 +1,27 @@
 package httpclient
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand/v2"
 	"net"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"tracer-test/pkg/clock"
+
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
@@ -16,23 +29,292 @@ import (
 
 // Client wraps the HTTP client with tracing
 type Client struct {
-	httpClient *http.Client
-	logger     *zap.Logger
-	tracer     trace.Tracer
+	httpClient      *http.Client
+	timeout         time.Duration
+	logger          *zap.Logger
+	tracer          trace.Tracer
+	bodyReadTimeout time.Duration
+	conns           *connTracker
+	clock           clock.Clock
+	etags           *etagCache
+	accept          string
+	envHeaders      *envHeaders
+	headers         http.Header
+	statusPolicy    *statusPolicy
+	jsonAttributes  map[string]string
+	compress        bool
+	injectionRate   float64
+	latency         time.Duration
+	latencyJitter   time.Duration
+	bearerToken     *tokenFile
+	baggage         *baggageCapture
+	scrubber        *urlScrubber
+	trailers        []string
+	verifyLength    bool
+	bodySnippet     int
+	dialTimeout     time.Duration
+	maxRetries      int
+	retryBackoff    time.Duration
+	bodyBudget      *bodyBudget
+	http3           *http3RoundTripper
+	// tracingDisabled is set for the no-op tracer to skip span attribute work
+	tracingDisabled bool
+	bytesRead       atomic.Int64
+	exhausted       atomic.Int64
+
+	streamProgressBytes int64
 }
 
+// ErrBodyReadTimeout is returned when reading a response body exceeds
+// the configured BodyReadTimeout
+var ErrBodyReadTimeout = errors.New("response body read timed out")
+
+// ErrInjectedFailure is returned in place of dispatching a request when
+// failure injection is enabled
+var ErrInjectedFailure = errors.New("injected failure")
+
 // Config holds HTTP client configuration
 type Config struct {
 	Timeout time.Duration
+	// BodyReadTimeout bounds the time spent reading a response body in
+	// ReadBody and GetBytes, independent of Timeout. Zero disables it.
+	BodyReadTimeout time.Duration
+	// Accept is sent as the Accept header on every request. Empty lets the
+	// server choose the representation.
+	Accept string
+	// EnvHeaders maps request header names to environment variables (e.g.
+	// "X-Deploy-Version" to "DEPLOY_VERSION") resolved once in New. Headers
+	// whose variable is unset or empty are omitted with a warning. Only
+	// their presence is recorded on spans, as http.request.env_header.<name>.
+	EnvHeaders map[string]string
+	// Headers are sent with every request, e.g. {"X-Api-Key": "..."}. Only
+	// their presence is recorded on spans, as http.request.header.<name>,
+	// so credentials never leak. Headers passed to GetWithHeaders or Do
+	// take precedence.
+	Headers map[string]string
+	// NonErrorStatusCodes lists status codes >= 400 that are expected and
+	// should not mark spans as errors or be logged as warnings (e.g. 404 for
+	// cache-miss probes)
+	NonErrorStatusCodes []int
+	// ExpectedStatusCodes, when set, lists the only status codes treated as
+	// success. Any other code, even below 400, marks the span as an error
+	// with http.unexpected_status=true and is logged as a warning.
+	// NonErrorStatusCodes is ignored when it is set.
+	ExpectedStatusCodes []int
+	// ResponseJSONAttributes maps JSONPath-ish expressions (e.g. ".status",
+	// ".build.version", ".items[0].id") to span attribute names. Values are
+	// extracted from JSON bodies read with ReadBody or GetBytes.
+	ResponseJSONAttributes map[string]string
+	// EnableConditionalGet caches ETags per URL and sends If-None-Match on
+	// subsequent requests. 304 Not Modified responses are treated as success.
+	EnableConditionalGet bool
+	// Clock provides the current time for durations. Defaults to the system clock.
+	Clock clock.Clock
+	// DetailSpanSampleRatio, when set, is the fraction (0-1) of requests
+	// that get the detailed http.transport, dns.resolve and tcp.connect
+	// spans, cutting span volume while the http.get span is always kept.
+	// Nil records detail spans for every request.
+	DetailSpanSampleRatio *float64
+	// AWSSigV4 enables AWS Signature Version 4 signing of outgoing requests
+	AWSSigV4 *AWSSigV4Config
+	// CompressRequestBody gzips request bodies and sets Content-Encoding: gzip
+	CompressRequestBody bool
+	// FailureInjectionRate is the probability (0-1) that a request fails with
+	// ErrInjectedFailure instead of being sent, for chaos testing. Zero disables it.
+	FailureInjectionRate float64
+	// InjectedLatency delays every request by this long plus a random
+	// amount up to InjectedLatencyJitter before it is sent, recorded as
+	// http.injected_latency_ms, for testing latency alerting. The delay
+	// ends early when the context is done. Zero disables it.
+	InjectedLatency       time.Duration
+	InjectedLatencyJitter time.Duration
+	// BearerTokenFile is read for a token sent as "Authorization: Bearer"
+	// on every request. The file is reread after BearerTokenTTL (default
+	// 10s) so rotated tokens are picked up without a restart.
+	BearerTokenFile string
+	BearerTokenTTL  time.Duration
+	// CaptureResponseBaggage lists response headers (e.g. "X-Trace-Context")
+	// recorded as http.response.header.<name> span attributes. Their latest
+	// values are sent as W3C baggage on subsequent requests.
+	CaptureResponseBaggage []string
+	// CaptureResponseTrailers lists response trailers (e.g. "Grpc-Status")
+	// recorded as http.response.trailer.<name> span attributes once the body
+	// has been read by ReadBody, GetBytes or GetStream.
+	CaptureResponseTrailers []string
+	// DialTimeout bounds establishing each TCP connection, recorded as
+	// net.dial.timeout_ms, so unreachable hosts fail fast. KeepAlive is the
+	// TCP keep-alive period; negative disables keep-alive probes. Both
+	// default to 30s.
+	DialTimeout time.Duration
+	KeepAlive   time.Duration
+	// SocketReadTimeout and SocketWriteTimeout bound each individual read
+	// and write on a TCP connection, so a peer that stalls mid-transfer is
+	// detected even while Timeout has time left. Such failures are
+	// classified as socket_timeout. Idle keep-alive connections are closed
+	// once SocketReadTimeout passes without data. HTTP/3 is not affected.
+	// Zero leaves reads or writes unbounded.
+	SocketReadTimeout  time.Duration
+	SocketWriteTimeout time.Duration
+	// MaxRetries retries idempotent requests (GET, HEAD, PUT, DELETE, ...)
+	// that fail with a network error or a 5xx status up to this many times,
+	// each attempt recorded as an http.<method>.attempt span with
+	// http.retry.count. Retries back off exponentially from RetryBackoff
+	// (default 100ms) with jitter, capped at 30s, and stop when the context
+	// is canceled or its deadline would pass before the next attempt.
+	// MaxRetries is at most 100.
+	MaxRetries   int
+	RetryBackoff time.Duration
+	// DNSCacheTTL caches successful DNS resolutions in process for the given
+	// duration, for both dns.resolve spans and connection dials. Zero disables it.
+	DNSCacheTTL time.Duration
+	// MaxConcurrentDNS limits the DNS lookups in flight at once, both for
+	// dns.resolve spans and connection dials, so bursts of requests don't
+	// overwhelm the resolver. Lookups that wait for a slot record
+	// dns.queue_wait_ms. Zero means unlimited.
+	MaxConcurrentDNS int
+	// ScrubQueryParams lists query parameters (e.g. "token") whose values
+	// are replaced with REDACTED in span attributes, logs and errors. The
+	// real URL is still sent. ScrubAllQueryParams drops the whole query.
+	ScrubQueryParams    []string
+	ScrubAllQueryParams bool
+	// StreamProgressBytes is how often GetStream records a progress span
+	// event. Defaults to 1 MiB.
+	StreamProgressBytes int64
+	// MinTLSVersion ("1.0", "1.1", "1.2" or "1.3") and CipherSuites (Go
+	// cipher suite names, e.g. "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256")
+	// restrict the TLS handshake. CipherSuites does not apply to TLS 1.3.
+	// Call Validate to reject invalid values at startup.
+	MinTLSVersion string
+	CipherSuites  []string
+	// TLSSessionCacheSize is the number of TLS sessions cached for
+	// resumption, recorded as tls.resumed on spans. Defaults to 32;
+	// negative disables the cache.
+	TLSSessionCacheSize int
+	// VerifyContentLength makes ReadBody compare a declared Content-Length
+	// with the bytes actually read, recording http.response.length_mismatch
+	// and failing the span when they differ.
+	VerifyContentLength bool
+	// EnableHTTP3 sends requests over HTTP/3 (QUIC), recording
+	// network.protocol.version and quic.handshake_ms on transport spans.
+	// Requests fall back to HTTP/2 or HTTP/1.1 when HTTP/3 fails, unless
+	// DisableHTTP3Fallback is set.
+	EnableHTTP3          bool
+	DisableHTTP3Fallback bool
+	// MaxTotalBodyMemory bounds the bytes of response bodies read by
+	// ReadBody and GetBytes at once across all requests. Each read reserves
+	// its Content-Length, or the whole budget when the length is unknown,
+	// and waits for room, recording http.body.memory_wait_ms. With
+	// BodyMemoryFailFast the read fails with ErrBodyMemoryExhausted instead
+	// of waiting. Zero disables the budget.
+	MaxTotalBodyMemory int64
+	BodyMemoryFailFast bool
+	// LogBodySnippetBytes, when positive, reads up to that many bytes from
+	// the start of error status response bodies into a body_snippet log
+	// field and an http.response.body_snippet span attribute. The body
+	// stays fully readable by the caller. Zero disables it.
+	LogBodySnippetBytes int
+}
+
+// Validate reports configuration errors that New cannot return
+func (c Config) Validate() error {
+	if c.MaxRetries < 0 || c.MaxRetries > maxRetriesLimit {
+		return fmt.Errorf("invalid max retries %d, must be between 0 and %d", c.MaxRetries, maxRetriesLimit)
+	}
+	_, err := newTLSConfig(c)
+	return err
 }
 
 // New creates a new HTTP client with tracing
 func New(config Config, logger *zap.Logger, tracer trace.Tracer) *Client {
+	clk := config.Clock
+	if clk == nil {
+		clk = clock.Real{}
+	}
+
+	// Every lookup, for spans or dials, goes through the limiter when set
+	var dnsLimit *dnsLimiter
+	lookup := lookupFunc(lookupIP)
+	if config.MaxConcurrentDNS > 0 {
+		dnsLimit = newDNSLimiter(config.MaxConcurrentDNS, clk, lookupIP)
+		lookup = dnsLimit.lookup
+	}
+	var dns *dnsCache
+	if config.DNSCacheTTL > 0 {
+		dns = newDNSCache(config.DNSCacheTTL, clk, lookup)
+	}
+
+	// Invalid TLS settings are rejected by Validate. Callers that skip it
+	// get the strictest policy rather than Go's defaults.
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		logger.Error("Invalid TLS settings, requiring TLS 1.3", zap.Error(err))
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS13}
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if config.DialTimeout > 0 {
+		dialer.Timeout = config.DialTimeout
+	}
+	if config.KeepAlive != 0 {
+		dialer.KeepAlive = config.KeepAlive
+	}
+	socketTimeouts := config.SocketReadTimeout > 0 || config.SocketWriteTimeout > 0
+	customDialer := config.DialTimeout > 0 || config.KeepAlive != 0 || socketTimeouts
+
+	base := http.DefaultTransport
+	if dns != nil || dnsLimit != nil || tlsConfig != nil || customDialer {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		if dns != nil {
+			t.DialContext = dns.dialContext(dialer)
+		} else if dnsLimit != nil {
+			t.DialContext = resolvingDial(dialer, dnsLimit.lookup)
+		} else if customDialer {
+			t.DialContext = dialer.DialContext
+		}
+		if socketTimeouts {
+			t.DialContext = socketDeadlines(t.DialContext, config.SocketReadTimeout, config.SocketWriteTimeout)
+		}
+		if tlsConfig != nil {
+			t.TLSClientConfig = tlsConfig
+		}
+		base = t
+	}
+	var h3 *http3RoundTripper
+	if config.EnableHTTP3 {
+		var fallback http.RoundTripper
+		if !config.DisableHTTP3Fallback {
+			fallback = base
+		}
+		h3 = newHTTP3RoundTripper(tlsConfig, fallback, clk)
+		base = h3
+	}
+	if config.AWSSigV4 != nil {
+		base = newSigV4Transport(base, *config.AWSSigV4)
+	}
+
+	conns := newConnTracker()
+	tracingDisabled := isNoopTracer(tracer)
+	policy := newStatusPolicy(config.NonErrorStatusCodes, config.ExpectedStatusCodes)
+	scrubber := newURLScrubber(config.ScrubQueryParams, config.ScrubAllQueryParams)
+
 	// Create instrumented transport
 	transport := &instrumentedTransport{
-		base:   http.DefaultTransport,
-		logger: logger,
-		tracer: tracer,
+		base:     base,
+		logger:   logger,
+		tracer:   tracer,
+		conns:    conns,
+		clock:    clk,
+		policy:   policy,
+		dns:      dns,
+		dnsLimit: dnsLimit,
+		scrubber: scrubber,
+
+		detailRatio: config.DetailSpanSampleRatio,
+
+		tracingDisabled: tracingDisabled,
 	}
 
 	// Create HTTP client with custom transport
@@ -41,72 +323,319 @@ func New(config Config, logger *zap.Logger, tracer trace.Tracer) *Client {
 		Timeout:   config.Timeout,
 	}
 
+	var etags *etagCache
+	if config.EnableConditionalGet {
+		etags = newETagCache()
+	}
+
+	var responseBaggage *baggageCapture
+	if len(config.CaptureResponseBaggage) > 0 {
+		responseBaggage = newBaggageCapture(config.CaptureResponseBaggage)
+	}
+
+	var bearerToken *tokenFile
+	if config.BearerTokenFile != "" {
+		bearerToken = newTokenFile(config.BearerTokenFile, config.BearerTokenTTL, clk)
+	}
+
 	return &Client{
-		httpClient: httpClient,
-		logger:     logger,
-		tracer:     tracer,
+		httpClient:      httpClient,
+		timeout:         config.Timeout,
+		logger:          logger,
+		tracer:          tracer,
+		bodyReadTimeout: config.BodyReadTimeout,
+		conns:           conns,
+		clock:           clk,
+		etags:           etags,
+		accept:          config.Accept,
+		envHeaders:      newEnvHeaders(config.EnvHeaders, logger),
+		headers:         newHeader(config.Headers),
+		statusPolicy:    policy,
+		jsonAttributes:  config.ResponseJSONAttributes,
+		compress:        config.CompressRequestBody,
+		injectionRate:   config.FailureInjectionRate,
+		latency:         config.InjectedLatency,
+		latencyJitter:   config.InjectedLatencyJitter,
+		bearerToken:     bearerToken,
+		baggage:         responseBaggage,
+		scrubber:        scrubber,
+		trailers:        config.CaptureResponseTrailers,
+		verifyLength:    config.VerifyContentLength,
+		bodySnippet:     config.LogBodySnippetBytes,
+		dialTimeout:     config.DialTimeout,
+		maxRetries:      config.MaxRetries,
+		retryBackoff:    config.RetryBackoff,
+		bodyBudget:      newBodyBudget(config.MaxTotalBodyMemory, config.BodyMemoryFailFast, clk),
+		http3:           h3,
+		tracingDisabled: tracingDisabled,
+
+		streamProgressBytes: config.StreamProgressBytes,
 	}
 }
 
 // Get makes a GET request with tracing
 func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
+	return c.do(ctx, http.MethodGet, url, nil, "", nil)
+}
+
+// GetWithHeaders makes a GET request with tracing, sending headers on top of
+// the configured Headers
+func (c *Client) GetWithHeaders(ctx context.Context, url string, headers map[string]string) (*http.Response, error) {
+	return c.do(ctx, http.MethodGet, url, newHeader(headers), "", nil)
+}
+
+// Post makes a POST request with tracing, compressing body when
+// CompressRequestBody is set
+func (c *Client) Post(ctx context.Context, url, contentType string, body []byte) (*http.Response, error) {
+	return c.do(ctx, http.MethodPost, url, nil, contentType, body)
+}
+
+// Do makes a bodyless request with the given method and extra headers, with
+// tracing. Headers managed by the client, such as the request ID, take
+// precedence.
+func (c *Client) Do(ctx context.Context, method, url string, header http.Header) (*http.Response, error) {
+	return c.do(ctx, method, url, header, "", nil)
+}
+
+// do makes a request with tracing
+func (c *Client) do(ctx context.Context, method, url string, header http.Header, contentType string, body []byte) (*http.Response, error) {
+	// Only the scrubbed URL is recorded in spans and logs
+	displayURL := c.ScrubURL(url)
+
 	// Create span for HTTP request
-	ctx, span := c.tracer.Start(ctx, "http.get",
-		trace.WithAttributes(
-			attribute.String("http.method", "GET"),
-			attribute.String("http.url", url),
-		))
-	defer span.End()
+	span := noopSpan
+	if !c.tracingDisabled {
+		ctx, span = c.tracer.Start(ctx, "http."+strings.ToLower(method),
+			trace.WithAttributes(
+				attribute.String("http.method", method),
+				attribute.String("http.url", displayURL),
+			))
+		defer span.End()
+	}
+
+	// Compress the body up front so Content-Length matches what is sent
+	encoding := ""
+	if body != nil {
+		uncompressedSize := len(body)
+		if c.compress {
+			compressed, err := gzipBody(body)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return nil, fmt.Errorf("failed to compress request body: %w", err)
+			}
+			body = compressed
+			encoding = "gzip"
+			span.SetAttributes(attribute.Int("http.request.uncompressed_size", uncompressedSize))
+		}
+		span.SetAttributes(attribute.Int("http.request.body_size", len(body)))
+	}
 
 	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	if header = mergeHeaders(c.headers, header); len(header) > 0 {
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		if !c.tracingDisabled {
+			recordHeaders(span, header)
+		}
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+
+	// Name the service being called
+	if name, ok := PeerServiceFromContext(ctx); ok && !c.tracingDisabled {
+		span.SetAttributes(attribute.String("peer.service", name))
+	}
+
+	// Propagate the request ID for correlation with the target's logs
+	if id, ok := RequestIDFromContext(ctx); ok {
+		req.Header.Set(RequestIDHeader, id)
+		if !c.tracingDisabled {
+			span.SetAttributes(attribute.String("request.id", id))
+		}
+	}
+
+	// Authenticate with the current token, never recording the token itself
+	if c.bearerToken != nil {
+		token, err := c.bearerToken.get()
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		span.SetAttributes(attribute.Bool("http.request.has_auth", true))
+	}
+
+	// Propagate the trace context in the registered formats
+	if !c.tracingDisabled {
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	}
+
+	// Carry correlation data captured from earlier responses
+	if c.baggage != nil {
+		c.baggage.inject(ctx, req)
+	}
+
+	// Request a specific representation
+	if c.accept != "" {
+		req.Header.Set("Accept", c.accept)
+		if !c.tracingDisabled {
+			span.SetAttributes(attribute.String("http.request.accept", c.accept))
+		}
+	}
+
+	// Send deploy metadata resolved from the environment
+	if c.envHeaders != nil {
+		c.envHeaders.apply(req, span, !c.tracingDisabled)
+	}
+
+	// Record the timeout that actually bounds this request
+	if !c.tracingDisabled {
+		if effective := c.effectiveTimeout(ctx, displayURL); effective > 0 {
+			span.SetAttributes(attribute.Int64("http.effective_timeout_ms", effective.Milliseconds()))
+		}
+		if c.dialTimeout > 0 {
+			span.SetAttributes(attribute.Int64("net.dial.timeout_ms", c.dialTimeout.Milliseconds()))
+		}
+	}
+
+	// Send the cached ETag for conditional requests
+	if c.etags != nil && method == http.MethodGet {
+		if etag, ok := c.etags.get(url); ok {
+			req.Header.Set("If-None-Match", etag)
+			span.SetAttributes(attribute.Bool("http.request.conditional", true))
+		}
+	}
+
+	// Short-circuit with a synthetic failure when injection is enabled
+	if c.injectionRate > 0 && rand.Float64() < c.injectionRate {
+		err := ErrInjectedFailure
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(attribute.Bool("error.injected", true))
+		c.logger.Warn("Injected request failure", zap.String("url", displayURL))
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+
+	// Delay the request to simulate a slow dependency
+	if c.latency > 0 || c.latencyJitter > 0 {
+		delay := c.injectedLatency()
+		span.SetAttributes(attribute.Int64("http.injected_latency_ms", delay.Milliseconds()))
+		if err := sleep(ctx, delay); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, fmt.Errorf("failed to make request: %w", err)
+		}
+	}
 
 	// Make the request
-	resp, err := c.httpClient.Do(req)
+	start := c.clock.Now()
+	resp, err := c.send(ctx, req, span)
 	if err != nil {
+		err = c.scrubber.scrubError(err)
+		failureType := classifyError(err)
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(attribute.String("error.category", failureType))
 		c.logger.Error("HTTP request failed",
-			zap.String("url", url),
+			zap.String("url", displayURL),
+			zap.String("failure_type", failureType),
 			zap.Error(err),
-			zap.Duration("duration", time.Since(time.Now())))
+			zap.Duration("duration", c.clock.Now().Sub(start)))
 		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
 
-	// Set span attributes based on response
-	contentLength := int(resp.ContentLength)
-	if contentLength < 0 {
-		contentLength = 0
+	if !c.tracingDisabled {
+		// Set span attributes based on response
+		contentLength := int(resp.ContentLength)
+		if contentLength < 0 {
+			contentLength = 0
+		}
+		span.SetAttributes(
+			semconv.HTTPResponseStatusCode(resp.StatusCode),
+			semconv.HTTPResponseSize(contentLength),
+		)
+
+		// Record the negotiated TLS parameters
+		recordTLSState(span, resp.TLS)
+
+		// Record where the request landed when redirects were followed
+		if resp.Request != nil && resp.Request.URL != nil {
+			if finalURL := c.scrubber.scrubURL(resp.Request.URL); finalURL != displayURL {
+				span.SetAttributes(attribute.String("http.response.final_url", finalURL))
+			}
+		}
+	}
+
+	// Capture correlation headers from the response
+	if c.baggage != nil {
+		c.baggage.capture(span, resp.Header)
+	}
+
+	// Remember the ETag and record cache hits
+	if c.etags != nil && method == http.MethodGet {
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			c.etags.set(url, etag)
+		}
+		if resp.StatusCode == http.StatusNotModified {
+			span.SetAttributes(attribute.Bool("http.response.not_modified", true))
+		}
 	}
-	span.SetAttributes(
-		semconv.HTTPResponseStatusCode(resp.StatusCode),
-		semconv.HTTPResponseSize(contentLength),
-	)
 
 	// Set span status based on HTTP status code
-	if resp.StatusCode >= 400 {
-		span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", resp.StatusCode))
+	if c.IsErrorStatus(resp.StatusCode) {
+		if !c.tracingDisabled {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", resp.StatusCode))
+		}
 		contentLength := resp.ContentLength
 		if contentLength < 0 {
 			contentLength = 0
 		}
-		c.logger.Warn("HTTP request returned error status",
-			zap.String("url", url),
+		message := "HTTP request returned error status"
+		if c.statusPolicy.isUnexpected(resp.StatusCode) {
+			span.SetAttributes(attribute.Bool("http.unexpected_status", true))
+			message = "HTTP request returned unexpected status"
+		}
+		fields := []zap.Field{
+			zap.String("url", displayURL),
 			zap.Int("status_code", resp.StatusCode),
-			zap.Int64("response_size", contentLength))
+			zap.Int64("response_size", contentLength),
+		}
+		// Show the start of the body to speed up debugging
+		if c.bodySnippet > 0 {
+			snippet := peekBody(resp, c.bodySnippet)
+			fields = append(fields, zap.String("body_snippet", snippet))
+			if !c.tracingDisabled {
+				span.SetAttributes(attribute.String("http.response.body_snippet", snippet))
+			}
+		}
+		c.logger.Warn(message, fields...)
 	} else {
-		span.SetStatus(codes.Ok, "")
+		if !c.tracingDisabled {
+			span.SetStatus(codes.Ok, "")
+		}
 		contentLength := resp.ContentLength
 		if contentLength < 0 {
 			contentLength = 0
 		}
 		c.logger.Info("HTTP request completed successfully",
-			zap.String("url", url),
+			zap.String("url", displayURL),
 			zap.Int("status_code", resp.StatusCode),
 			zap.Int64("response_size", contentLength))
 	}
@@ -114,20 +643,191 @@ func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
 	return resp, nil
 }
 
+// effectiveTimeout returns the tighter of the client timeout and the time
+// remaining until the context deadline. Zero means unbounded.
+func (c *Client) effectiveTimeout(ctx context.Context, url string) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return c.timeout
+	}
+
+	remaining := deadline.Sub(c.clock.Now())
+	if c.timeout > 0 && c.timeout <= remaining {
+		return c.timeout
+	}
+
+	c.logger.Debug("Context deadline governs request timeout",
+		zap.String("url", url),
+		zap.Duration("context_remaining", remaining),
+		zap.Duration("client_timeout", c.timeout))
+	return remaining
+}
+
+// ScrubURL returns url with the configured query parameters redacted, for
+// recording outside the client
+func (c *Client) ScrubURL(url string) string {
+	return c.scrubber.scrub(url)
+}
+
+// IsErrorStatus reports whether the client treats an HTTP status code as an error
+func (c *Client) IsErrorStatus(code int) bool {
+	return c.statusPolicy.isError(code)
+}
+
+// GetBytes makes a GET request with tracing and reads the full response body.
+// The returned response body has already been closed.
+func (c *Client) GetBytes(ctx context.Context, url string) (*http.Response, []byte, error) {
+	resp, err := c.Get(ctx, url)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, err := c.ReadBody(ctx, resp)
+	if err != nil {
+		return resp, body, err
+	}
+	return resp, body, nil
+}
+
+// ReadBody reads and closes the response body, applying BodyReadTimeout
+// when configured. Failures are recorded on the span carried by ctx.
+func (c *Client) ReadBody(ctx context.Context, resp *http.Response) ([]byte, error) {
+	defer resp.Body.Close()
+
+	span := trace.SpanFromContext(ctx)
+
+	// Reserve room for the body in the memory budget
+	if c.bodyBudget != nil {
+		release, waited, err := c.bodyBudget.acquire(ctx, resp)
+		span.SetAttributes(attribute.Int64("http.body.memory_wait_ms", waited.Milliseconds()))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		defer release()
+	}
+
+	var timedOut atomic.Bool
+	if c.bodyReadTimeout > 0 {
+		// Closing the body unblocks any in-progress read
+		timer := time.AfterFunc(c.bodyReadTimeout, func() {
+			timedOut.Store(true)
+			resp.Body.Close()
+		})
+		defer timer.Stop()
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	c.bytesRead.Add(int64(len(body)))
+	if c.verifyLength {
+		c.checkContentLength(span, resp, len(body))
+	}
+	if err != nil {
+		if timedOut.Load() {
+			err = fmt.Errorf("%w after %s", ErrBodyReadTimeout, c.bodyReadTimeout)
+			span.SetAttributes(attribute.String("error.category", "body_read_timeout"))
+		} else if isSocketTimeout(err) {
+			span.SetAttributes(attribute.String("error.category", failureSocketTimeout))
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return body, err
+	}
+
+	c.recordJSONAttributes(span, body)
+	c.recordTrailers(span, resp.Trailer)
+
+	return body, nil
+}
+
+// checkContentLength flags a response whose body length differs from its
+// declared Content-Length. Unknown lengths and bodyless responses are skipped.
+func (c *Client) checkContentLength(span trace.Span, resp *http.Response, actual int) {
+	if resp.ContentLength < 0 || resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified ||
+		(resp.Request != nil && resp.Request.Method == http.MethodHead) {
+		return
+	}
+	if int64(actual) == resp.ContentLength {
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Bool("http.response.length_mismatch", true),
+		attribute.Int64("http.response.declared_length", resp.ContentLength),
+		attribute.Int("http.response.actual_length", actual),
+	)
+	span.SetStatus(codes.Error, fmt.Sprintf("content length mismatch: declared %d, read %d", resp.ContentLength, actual))
+	c.logger.Warn("Response body length does not match Content-Length",
+		zap.Int64("declared_length", resp.ContentLength),
+		zap.Int("actual_length", actual))
+}
+
+// recordJSONAttributes extracts the configured JSON fields from body onto span.
+// Non-JSON bodies and missing paths are skipped.
+func (c *Client) recordJSONAttributes(span trace.Span, body []byte) {
+	if len(c.jsonAttributes) == 0 {
+		return
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		c.logger.Debug("Response body is not JSON, skipping attribute extraction", zap.Error(err))
+		return
+	}
+
+	for path, name := range c.jsonAttributes {
+		value, ok := lookupJSONPath(doc, path)
+		if !ok {
+			c.logger.Debug("JSON path not found in response", zap.String("path", path))
+			continue
+		}
+		span.SetAttributes(jsonAttribute(name, value))
+	}
+}
+
 // instrumentedTransport wraps http.RoundTripper with detailed instrumentation
 type instrumentedTransport struct {
-	base   http.RoundTripper
-	logger *zap.Logger
-	tracer trace.Tracer
+	base     http.RoundTripper
+	logger   *zap.Logger
+	tracer   trace.Tracer
+	conns    *connTracker
+	clock    clock.Clock
+	policy   *statusPolicy
+	dns      *dnsCache
+	dnsLimit *dnsLimiter
+	scrubber *urlScrubber
+	// detailRatio is the fraction of requests that get detail spans, nil
+	// for all of them
+	detailRatio *float64
+
+	tracingDisabled bool
+}
+
+// sampleDetail decides whether a request gets detail spans
+func (t *instrumentedTransport) sampleDetail() bool {
+	return t.detailRatio == nil || rand.Float64() < *t.detailRatio
 }
 
 // RoundTrip implements http.RoundTripper interface
 func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Without tracing, or for requests sampled out of detail spans, skip the
+	// spans and the extra DNS lookup that feeds them
+	if t.tracingDisabled || !t.sampleDetail() {
+		if t.conns == nil {
+			return t.base.RoundTrip(req)
+		}
+		host, port := HostPort(req.URL)
+		ctx, use := t.conns.track(req.Context(), net.JoinHostPort(host, port))
+		resp, err := t.base.RoundTrip(req.WithContext(ctx))
+		return use.finish(resp, err), err
+	}
+
 	// Create span for HTTP transport
 	ctx, span := t.tracer.Start(req.Context(), "http.transport",
 		trace.WithAttributes(
 			attribute.String("http.method", req.Method),
-			attribute.String("http.url", req.URL.String()),
+			attribute.String("http.url", t.scrubber.scrubURL(req.URL)),
 		))
 	defer span.End()
 
@@ -135,25 +835,42 @@ func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, er
 	req = req.WithContext(ctx)
 
 	// Perform DNS resolution
-	host := req.URL.Hostname()
-	port := req.URL.Port()
-	if port == "" {
-		if req.URL.Scheme == "https" {
-			port = "443"
-		} else {
-			port = "80"
-		}
+	host, port := HostPort(req.URL)
+
+	// Track connection pool usage for this host
+	var use *connUse
+	if t.conns != nil {
+		var connCtx context.Context
+		connCtx, use = t.conns.track(req.Context(), net.JoinHostPort(host, port))
+		req = req.WithContext(connCtx)
 	}
 
 	// DNS resolution span
-	_, dnsSpan := t.tracer.Start(ctx, "dns.resolve",
+	dnsCtx, dnsSpan := t.tracer.Start(ctx, "dns.resolve",
 		trace.WithAttributes(
 			attribute.String("dns.hostname", host),
 		))
-	
-	start := time.Now()
-	ips, err := net.LookupIP(host)
-	dnsDuration := time.Since(start)
+
+	// Lookups wait for a slot when concurrent lookups are limited
+	start := t.now()
+	var (
+		ips []net.IP
+		err error
+	)
+	if t.dns != nil {
+		var hit bool
+		ips, hit, err = t.dns.resolve(dnsCtx, host)
+		if hit {
+			dnsSpan.SetAttributes(attribute.String("dns.cache", "hit"))
+		} else {
+			dnsSpan.SetAttributes(attribute.String("dns.cache", "miss"))
+		}
+	} else if t.dnsLimit != nil {
+		ips, err = t.dnsLimit.lookup(dnsCtx, host)
+	} else {
+		ips, err = lookupIP(dnsCtx, host)
+	}
+	dnsDuration := t.now().Sub(start)
 	
 	if err != nil {
 		dnsSpan.RecordError(err)
@@ -175,9 +892,9 @@ func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, er
 		))
 
 	// Make the actual HTTP request
-	start = time.Now()
+	start = t.now()
 	resp, err := t.base.RoundTrip(req)
-	httpDuration := time.Since(start)
+	httpDuration := t.now().Sub(start)
 
 	if err != nil {
 		tcpSpan.RecordError(err)
@@ -198,9 +915,10 @@ func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, er
 			semconv.HTTPResponseStatusCode(resp.StatusCode),
 			semconv.HTTPResponseSize(contentLength),
 			attribute.Int64("http.duration_ms", httpDuration.Milliseconds()),
+			attribute.String("network.protocol.version", protocolVersion(resp)),
 		)
 		
-		if resp.StatusCode >= 400 {
+		if t.policy.isError(resp.StatusCode) {
 			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", resp.StatusCode))
 		} else {
 			span.SetStatus(codes.Ok, "")
@@ -208,9 +926,34 @@ func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, er
 	}
 	tcpSpan.End()
 
+	if use != nil {
+		resp = use.finish(resp, err)
+	}
 	return resp, err
 }
 
+// now returns the current time from the transport's clock
+func (t *instrumentedTransport) now() time.Time {
+	if t.clock == nil {
+		return time.Now()
+	}
+	return t.clock.Now()
+}
+
+// HostPort returns the host and port targeted by u, defaulting the port
+// from the scheme when it is not explicit
+func HostPort(u *url.URL) (string, string) {
+	port := u.Port()
+	if port == "" {
+		if u.Scheme == "https" {
+			port = "443"
+		} else {
+			port = "80"
+		}
+	}
+	return u.Hostname(), port
+}
+
 // ipToStrings converts []net.IP to []string
 func ipToStrings(ips []net.IP) []string {
 	result := make([]string, len(ips))
@@ -220,8 +963,28 @@ func ipToStrings(ips []net.IP) []string {
 	return result
 }
 
+// ConnStats returns the current idle and active connection counts keyed
+// by host:port
+func (c *Client) ConnStats() map[string]ConnStats {
+	return c.conns.snapshot()
+}
+
+// BytesRead returns the response body bytes read by ReadBody, GetBytes and
+// GetStream over the client's lifetime
+func (c *Client) BytesRead() int64 {
+	return c.bytesRead.Load()
+}
+
+// Clock returns the clock used by the client
+func (c *Client) Clock() clock.Clock {
+	return c.clock
+}
+
 // Close closes the HTTP client
 func (c *Client) Close() {
 	// Close any idle connections
 	c.httpClient.CloseIdleConnections()
+	if c.http3 != nil {
+		_ = c.http3.Close()
+	}
 }
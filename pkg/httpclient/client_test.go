@@ -1,12 +1,31 @@
 package httpclient
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"tracer-test/pkg/clock"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.opentelemetry.io/otel/trace/noop"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -302,3 +321,2019 @@ func TestClient_Timeout(t *testing.T) {
 		t.Error("Expected to find 'HTTP request failed' log message")
 	}
 }
+
+func TestClient_Get_AWSSigV4(t *testing.T) {
+	// Create a test server that captures the Authorization header
+	var authHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a no-op tracer
+	tracer := noop.NewTracerProvider().Tracer("test")
+
+	config := Config{
+		Timeout: 5 * time.Second,
+		AWSSigV4: &AWSSigV4Config{
+			Region:      "us-east-1",
+			Service:     "execute-api",
+			Credentials: credentials.NewStaticCredentialsProvider("AKIDEXAMPLE", "secret", ""),
+		},
+	}
+
+	client := New(config, logger, tracer)
+	defer client.Close()
+
+	resp, err := client.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if !strings.HasPrefix(authHeader, "AWS4-HMAC-SHA256") {
+		t.Errorf("Authorization header = %q, expected AWS4-HMAC-SHA256 signature", authHeader)
+	}
+	if !strings.Contains(authHeader, "Credential=AKIDEXAMPLE/") {
+		t.Errorf("Authorization header = %q, expected to contain access key", authHeader)
+	}
+}
+
+func TestClient_ReadBody_BodyReadTimeout(t *testing.T) {
+	// Create a test server that sends headers immediately but dribbles the body
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		for i := 0; i < 20; i++ {
+			_, _ = w.Write([]byte("x"))
+			w.(http.Flusher).Flush()
+			time.Sleep(50 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	config := Config{
+		Timeout:         5 * time.Second,
+		BodyReadTimeout: 200 * time.Millisecond,
+	}
+
+	client := New(config, logger, tracer)
+	defer client.Close()
+
+	ctx, span := tracer.Start(context.Background(), "request.cycle")
+	start := time.Now()
+	_, _, err := client.GetBytes(ctx, server.URL)
+	elapsed := time.Since(start)
+	span.End()
+
+	if !errors.Is(err, ErrBodyReadTimeout) {
+		t.Fatalf("GetBytes() error = %v, expected ErrBodyReadTimeout", err)
+	}
+	if elapsed > 800*time.Millisecond {
+		t.Errorf("GetBytes() took %s, expected body read timeout to fire early", elapsed)
+	}
+
+	// Check that the error category was recorded on the cycle span
+	found := false
+	for _, s := range recorder.Ended() {
+		if s.Name() != "request.cycle" {
+			continue
+		}
+		for _, attr := range s.Attributes() {
+			if attr.Key == "error.category" && attr.Value.AsString() == "body_read_timeout" {
+				found = true
+			}
+		}
+	}
+	if !found {
+		t.Error("Expected request.cycle span to have error.category=body_read_timeout")
+	}
+}
+
+func TestClient_ConnStats(t *testing.T) {
+	// Create a test server that holds requests briefly to force concurrent connections
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("test response"))
+	}))
+	defer server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a no-op tracer
+	tracer := noop.NewTracerProvider().Tracer("test")
+
+	client := New(Config{Timeout: 5 * time.Second}, logger, tracer)
+	defer client.Close()
+
+	// Issue concurrent requests to the same host
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := client.GetBytes(context.Background(), server.URL); err != nil {
+				t.Errorf("GetBytes() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Give the transport time to return connections to the pool
+	time.Sleep(100 * time.Millisecond)
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	stats, ok := client.ConnStats()[host]
+	if !ok {
+		t.Fatalf("ConnStats() has no entry for %s", host)
+	}
+	if stats.Idle == 0 {
+		t.Errorf("ConnStats() idle = %d, expected idle connections to accumulate", stats.Idle)
+	}
+	if stats.Active != 0 {
+		t.Errorf("ConnStats() active = %d, expected 0 after requests completed", stats.Active)
+	}
+}
+
+func TestClient_ConnStats_HTTP2(t *testing.T) {
+	// Create an HTTP/2 test server; HTTP/2 never returns connections to the
+	// idle pool
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("test response"))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{Timeout: 5 * time.Second}, logger, tracer)
+	defer client.Close()
+
+	// Trust the test server's certificate
+	base := client.httpClient.Transport.(*instrumentedTransport).base.(*http.Transport)
+	base.TLSClientConfig.RootCAs = server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(context.Background(), server.URL)
+			if err != nil {
+				t.Errorf("Get() error = %v", err)
+				return
+			}
+			if resp.ProtoMajor != 2 {
+				t.Errorf("Response protocol = %s, expected HTTP/2", resp.Proto)
+			}
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	host := strings.TrimPrefix(server.URL, "https://")
+	if stats := client.ConnStats()[host]; stats.Active != 0 {
+		t.Errorf("ConnStats() active = %d, expected 0 after requests completed", stats.Active)
+	}
+}
+
+func TestClient_ConnStats_ConnectionClose(t *testing.T) {
+	// Create a test server that closes each connection instead of keeping it alive
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Connection", "close")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a no-op tracer
+	tracer := noop.NewTracerProvider().Tracer("test")
+
+	client := New(Config{Timeout: 5 * time.Second}, logger, tracer)
+	defer client.Close()
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := client.GetBytes(context.Background(), server.URL); err != nil {
+			t.Fatalf("GetBytes() error = %v", err)
+		}
+	}
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	stats := client.ConnStats()[host]
+	if stats.Active != 0 || stats.Idle != 0 {
+		t.Errorf("ConnStats() = %+v, expected no active or idle connections", stats)
+	}
+}
+
+func TestClient_Get_ConditionalGet(t *testing.T) {
+	// Create a test server that returns an ETag and honors If-None-Match
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("test response"))
+	}))
+	defer server.Close()
+
+	// Create a test logger with observer
+	core, recorded := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	config := Config{
+		Timeout:              5 * time.Second,
+		EnableConditionalGet: true,
+	}
+
+	client := New(config, logger, tracer)
+	defer client.Close()
+
+	// First request populates the ETag cache
+	if _, _, err := client.GetBytes(context.Background(), server.URL); err != nil {
+		t.Fatalf("GetBytes() error = %v", err)
+	}
+
+	// Second request should be answered with 304
+	resp, _, err := client.GetBytes(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("GetBytes() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusNotModified {
+		t.Fatalf("GetBytes() status = %d, expected %d", resp.StatusCode, http.StatusNotModified)
+	}
+
+	// Check the not-modified span is marked as a successful cache hit
+	spans := recorder.Ended()
+	last := spans[len(spans)-1]
+	if last.Name() != "http.get" {
+		t.Fatalf("Last span = %s, expected http.get", last.Name())
+	}
+	if last.Status().Code != codes.Ok {
+		t.Errorf("http.get span status = %v, expected Ok", last.Status().Code)
+	}
+	found := false
+	for _, attr := range last.Attributes() {
+		if attr.Key == "http.response.not_modified" && attr.Value.AsBool() {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected http.get span to have http.response.not_modified=true")
+	}
+
+	// Check that 304 was not logged as an error
+	if n := recorded.FilterMessage("HTTP request returned error status").Len(); n != 0 {
+		t.Errorf("Found %d error status logs, expected 0", n)
+	}
+}
+
+func TestClient_Get_EffectiveTimeout(t *testing.T) {
+	// Create a test server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Create a test logger with observer at debug level
+	core, recorded := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{Timeout: 30 * time.Second}, logger, tracer)
+	defer client.Close()
+
+	// Use a context deadline much tighter than the client timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	resp, err := client.Get(ctx, server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	// Check the effective timeout reflects the context deadline
+	var effective int64 = -1
+	for _, s := range recorder.Ended() {
+		if s.Name() != "http.get" {
+			continue
+		}
+		for _, attr := range s.Attributes() {
+			if attr.Key == "http.effective_timeout_ms" {
+				effective = attr.Value.AsInt64()
+			}
+		}
+	}
+	if effective <= 0 || effective > 500 {
+		t.Errorf("http.effective_timeout_ms = %d, expected between 1 and 500", effective)
+	}
+
+	// Check that the governing deadline was logged
+	if recorded.FilterMessage("Context deadline governs request timeout").Len() == 0 {
+		t.Error("Expected to find 'Context deadline governs request timeout' log message")
+	}
+}
+
+func TestClient_Get_ConnectionRefused(t *testing.T) {
+	// Reserve a port and close it so connections are refused
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	// Create a test logger with observer
+	core, recorded := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a no-op tracer
+	tracer := noop.NewTracerProvider().Tracer("test")
+
+	client := New(Config{Timeout: 5 * time.Second}, logger, tracer)
+	defer client.Close()
+
+	if _, err := client.Get(context.Background(), "http://"+addr); err == nil {
+		t.Fatal("Get() expected connection refused error")
+	}
+
+	// Check the failure type on the error log
+	logs := recorded.FilterMessage("HTTP request failed").All()
+	if len(logs) == 0 {
+		t.Fatal("Expected to find 'HTTP request failed' log message")
+	}
+	if failureType := logs[0].ContextMap()["failure_type"]; failureType != "connect" {
+		t.Errorf("failure_type = %v, expected connect", failureType)
+	}
+}
+
+func TestClient_Get_Accept(t *testing.T) {
+	// Create a test server that captures the Accept header
+	var acceptHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptHeader = r.Header.Get("Accept")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a no-op tracer
+	tracer := noop.NewTracerProvider().Tracer("test")
+
+	client := New(Config{
+		Timeout: 5 * time.Second,
+		Accept:  "application/json",
+	}, logger, tracer)
+	defer client.Close()
+
+	resp, err := client.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if acceptHeader != "application/json" {
+		t.Errorf("Server received Accept = %q, expected %q", acceptHeader, "application/json")
+	}
+}
+
+func TestClient_Get_NonErrorStatusCodes(t *testing.T) {
+	// Create a test server that returns 404
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	// Create a test logger with observer
+	core, recorded := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{
+		Timeout:             5 * time.Second,
+		NonErrorStatusCodes: []int{http.StatusNotFound},
+	}, logger, tracer)
+	defer client.Close()
+
+	resp, err := client.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	// Check that no span was marked as an error
+	for _, s := range recorder.Ended() {
+		if s.Status().Code != codes.Ok {
+			t.Errorf("%s span status = %v, expected Ok", s.Name(), s.Status().Code)
+		}
+	}
+
+	// Check that the response was logged at info level
+	logs := recorded.FilterMessage("HTTP request completed successfully").All()
+	if len(logs) != 1 || logs[0].Level != zapcore.InfoLevel {
+		t.Error("Expected 404 to be logged as 'HTTP request completed successfully' at info level")
+	}
+	if recorded.FilterMessage("HTTP request returned error status").Len() != 0 {
+		t.Error("Expected no 'HTTP request returned error status' log message")
+	}
+}
+
+func TestClient_Get_ExpectedStatusCodes(t *testing.T) {
+	// Create a test server that returns 204
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// Create a test logger with observer
+	core, recorded := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{
+		Timeout:             5 * time.Second,
+		ExpectedStatusCodes: []int{http.StatusOK},
+	}, logger, tracer)
+	defer client.Close()
+
+	resp, err := client.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if !client.IsErrorStatus(http.StatusNoContent) {
+		t.Error("IsErrorStatus(204) = false, expected true when only 200 is expected")
+	}
+
+	// Check that the request span was flagged as unexpected
+	for _, s := range recorder.Ended() {
+		if s.Name() != "http.get" {
+			continue
+		}
+		if s.Status().Code != codes.Error {
+			t.Errorf("http.get span status = %v, expected Error", s.Status().Code)
+		}
+		unexpected := false
+		for _, attr := range s.Attributes() {
+			if attr.Key == "http.unexpected_status" && attr.Value.AsBool() {
+				unexpected = true
+			}
+		}
+		if !unexpected {
+			t.Error("Expected http.get span to have http.unexpected_status=true")
+		}
+	}
+
+	// Check that the response was logged as a warning
+	logs := recorded.FilterMessage("HTTP request returned unexpected status").All()
+	if len(logs) != 1 || logs[0].Level != zapcore.WarnLevel {
+		t.Error("Expected 204 to be logged as 'HTTP request returned unexpected status' at warn level")
+	}
+}
+
+func TestClient_GetWithHeaders(t *testing.T) {
+	// Create a test server that records the request headers
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{
+		Timeout: 5 * time.Second,
+		Headers: map[string]string{
+			"Authorization": "Bearer secret-token",
+			"X-Api-Key":     "secret-key",
+			"X-Tenant":      "default",
+		},
+	}, logger, tracer)
+	defer client.Close()
+
+	// Default headers are sent with every request
+	resp, err := client.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	expected := map[string]string{
+		"Authorization": "Bearer secret-token",
+		"X-Api-Key":     "secret-key",
+		"X-Tenant":      "default",
+	}
+	for name, value := range expected {
+		if got := received.Get(name); got != value {
+			t.Errorf("Get() sent %s = %q, expected %q", name, got, value)
+		}
+	}
+
+	// Per-call headers are merged over the defaults
+	resp, err = client.GetWithHeaders(context.Background(), server.URL, map[string]string{
+		"x-tenant":  "acme",
+		"X-Request": "one-off",
+	})
+	if err != nil {
+		t.Fatalf("GetWithHeaders() error = %v", err)
+	}
+	resp.Body.Close()
+
+	expected["X-Tenant"] = "acme"
+	expected["X-Request"] = "one-off"
+	for name, value := range expected {
+		if got := received.Get(name); got != value {
+			t.Errorf("GetWithHeaders() sent %s = %q, expected %q", name, got, value)
+		}
+	}
+
+	// Check that the span records which headers were sent, never their values
+	spans := recorder.Ended()
+	var last sdktrace.ReadOnlySpan
+	for _, s := range spans {
+		if s.Name() != "http.get" {
+			continue
+		}
+		last = s
+		for _, attr := range s.Attributes() {
+			if value := attr.Value.Emit(); strings.Contains(value, "secret") || strings.Contains(value, "acme") {
+				t.Errorf("Attribute %s = %s leaks a credential", attr.Key, value)
+			}
+		}
+	}
+	if last == nil {
+		t.Fatal("Expected an http.get span")
+	}
+	attrs := map[string]string{}
+	for _, attr := range last.Attributes() {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	expectedAttrs := map[string]string{
+		"http.request.header.authorization": "true",
+		"http.request.header.x-api-key":     "true",
+		"http.request.header.x-tenant":      "true",
+		"http.request.header.x-request":     "true",
+	}
+	for key, value := range expectedAttrs {
+		if attrs[key] != value {
+			t.Errorf("%s = %s, expected %s", key, attrs[key], value)
+		}
+	}
+}
+
+func TestClient_Get_EnvHeaders(t *testing.T) {
+	t.Setenv("TEST_DEPLOY_VERSION", "v1.2.3")
+
+	// Create a test server that records the request headers
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Create a test logger with observer
+	core, recorded := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{
+		Timeout: 5 * time.Second,
+		EnvHeaders: map[string]string{
+			"X-Deploy-Version": "TEST_DEPLOY_VERSION",
+			"X-Deploy-Region":  "TEST_DEPLOY_REGION_UNSET",
+		},
+	}, logger, tracer)
+	defer client.Close()
+
+	resp, err := client.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if got := received.Get("X-Deploy-Version"); got != "v1.2.3" {
+		t.Errorf("X-Deploy-Version = %q, expected %q", got, "v1.2.3")
+	}
+	if _, ok := received["X-Deploy-Region"]; ok {
+		t.Error("Expected X-Deploy-Region to be omitted when its variable is unset")
+	}
+
+	// Check that the missing variable was logged
+	if n := recorded.FilterMessage("Environment variable for request header is not set, omitting header").Len(); n != 1 {
+		t.Errorf("Logged %d missing variable warnings, expected 1", n)
+	}
+
+	// Check that presence, not the value, was recorded on the span
+	for _, s := range recorder.Ended() {
+		if s.Name() != "http.get" {
+			continue
+		}
+		present := false
+		for _, attr := range s.Attributes() {
+			if attr.Key == "http.request.env_header.x-deploy-version" && attr.Value.AsBool() {
+				present = true
+			}
+			if attr.Value.Emit() == "v1.2.3" {
+				t.Errorf("Attribute %s leaks the header value", attr.Key)
+			}
+		}
+		if !present {
+			t.Error("Expected http.get span to have http.request.env_header.x-deploy-version=true")
+		}
+	}
+}
+
+func TestClient_Get_B3Propagation(t *testing.T) {
+	// Register B3 multi-header propagation, restoring the previous propagator
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+	defer otel.SetTextMapPropagator(previous)
+
+	// Create a test server that records the request headers
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Create a test logger with observer
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{Timeout: 5 * time.Second}, logger, tracer)
+	defer client.Close()
+
+	resp, err := client.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	// The request carries the http.get span in B3 headers only
+	var traceID string
+	for _, s := range recorder.Ended() {
+		if s.Name() == "http.get" {
+			traceID = s.SpanContext().TraceID().String()
+		}
+	}
+	if got := received.Get("X-B3-TraceId"); got == "" || got != traceID {
+		t.Errorf("X-B3-TraceId = %q, expected %q", got, traceID)
+	}
+	if received.Get("X-B3-SpanId") == "" {
+		t.Error("Expected an X-B3-SpanId header")
+	}
+	if got := received.Get("Traceparent"); got != "" {
+		t.Errorf("Traceparent = %q, expected none with B3 propagation", got)
+	}
+}
+
+func TestClient_Get_TraceContextPropagation(t *testing.T) {
+	// Register W3C trace context propagation, restoring the previous propagator
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	defer otel.SetTextMapPropagator(previous)
+
+	// Create a test server that records the request headers
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{Timeout: 5 * time.Second}, zap.NewNop(), tracer)
+	defer client.Close()
+
+	resp, err := client.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	// The request carries the http.get span as its traceparent
+	var expected string
+	for _, s := range recorder.Ended() {
+		if s.Name() == "http.get" {
+			expected = "00-" + s.SpanContext().TraceID().String() + "-" + s.SpanContext().SpanID().String() + "-01"
+		}
+	}
+	if got := received.Get("Traceparent"); got == "" || got != expected {
+		t.Errorf("Traceparent = %q, expected %q", got, expected)
+	}
+}
+
+func TestClient_Get_InjectedLatencyCanceled(t *testing.T) {
+	// Create a test server that counts requests
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	client := New(Config{
+		Timeout:               5 * time.Second,
+		InjectedLatency:       10 * time.Second,
+		InjectedLatencyJitter: time.Second,
+	}, logger, noop.NewTracerProvider().Tracer("test"))
+	defer client.Close()
+
+	// The delay ends with the context instead of running its course
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.Get(ctx, server.URL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get() error = %v, expected context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Get() took %s, expected to return when the context ended", elapsed)
+	}
+	if hits != 0 {
+		t.Errorf("Server received %d requests, expected 0", hits)
+	}
+}
+
+func TestClient_Get_LogBodySnippet(t *testing.T) {
+	// Create a test server that fails with a body
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("database unavailable"))
+	}))
+	defer server.Close()
+
+	// Create a test logger with observer
+	core, recorded := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{
+		Timeout:             5 * time.Second,
+		LogBodySnippetBytes: 8,
+	}, logger, tracer)
+	defer client.Close()
+
+	resp, body, err := client.GetBytes(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("GetBytes() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("StatusCode = %d, expected %d", resp.StatusCode, http.StatusInternalServerError)
+	}
+
+	// The caller still reads the whole body
+	if string(body) != "database unavailable" {
+		t.Errorf("GetBytes() body = %q, expected %q", body, "database unavailable")
+	}
+
+	// Check that the warning carries the snippet
+	logs := recorded.FilterMessage("HTTP request returned error status").All()
+	if len(logs) != 1 {
+		t.Fatalf("Logged %d error status warnings, expected 1", len(logs))
+	}
+	if got := logs[0].ContextMap()["body_snippet"]; got != "database" {
+		t.Errorf("body_snippet = %q, expected %q", got, "database")
+	}
+
+	// Check that the snippet was recorded on the span
+	for _, s := range recorder.Ended() {
+		if s.Name() != "http.get" {
+			continue
+		}
+		found := false
+		for _, attr := range s.Attributes() {
+			if attr.Key == "http.response.body_snippet" && attr.Value.AsString() == "database" {
+				found = true
+			}
+		}
+		if !found {
+			t.Error("Expected http.get span to have http.response.body_snippet=database")
+		}
+	}
+}
+
+func TestClient_ReadBody_ResponseJSONAttributes(t *testing.T) {
+	// Create a test server returning JSON
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"ok","build":{"version":"1.2.3"}}`))
+	}))
+	defer server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{
+		Timeout: 5 * time.Second,
+		ResponseJSONAttributes: map[string]string{
+			".status":        "response.status",
+			".build.version": "response.version",
+			".missing":       "response.missing",
+		},
+	}, logger, tracer)
+	defer client.Close()
+
+	ctx, span := tracer.Start(context.Background(), "request.cycle")
+	if _, _, err := client.GetBytes(ctx, server.URL); err != nil {
+		t.Fatalf("GetBytes() error = %v", err)
+	}
+	span.End()
+
+	// Check the extracted attributes on the cycle span
+	attrs := map[string]string{}
+	for _, s := range recorder.Ended() {
+		if s.Name() != "request.cycle" {
+			continue
+		}
+		for _, attr := range s.Attributes() {
+			attrs[string(attr.Key)] = attr.Value.Emit()
+		}
+	}
+	if attrs["response.status"] != "ok" {
+		t.Errorf("response.status = %q, expected %q", attrs["response.status"], "ok")
+	}
+	if attrs["response.version"] != "1.2.3" {
+		t.Errorf("response.version = %q, expected %q", attrs["response.version"], "1.2.3")
+	}
+	if _, ok := attrs["response.missing"]; ok {
+		t.Error("Expected missing JSON path to be skipped")
+	}
+}
+
+func TestClient_ReadBody_MaxTotalBodyMemory(t *testing.T) {
+	// Create a test server whose 1000-byte body takes a while to arrive
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "1000")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(bytes.Repeat([]byte("x"), 500))
+		w.(http.Flusher).Flush()
+		time.Sleep(300 * time.Millisecond)
+		_, _ = w.Write(bytes.Repeat([]byte("x"), 500))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		failFast bool
+	}{
+		{"wait", false},
+		{"fail fast", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a test logger
+			core, _ := observer.New(zapcore.InfoLevel)
+			logger := zap.New(core)
+
+			// Create a recording tracer
+			recorder := tracetest.NewSpanRecorder()
+			tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+			// The budget fits only one body at a time
+			client := New(Config{
+				Timeout:            5 * time.Second,
+				MaxTotalBodyMemory: 1000,
+				BodyMemoryFailFast: tt.failFast,
+			}, logger, tracer)
+			defer client.Close()
+
+			var wg sync.WaitGroup
+			errs := make([]error, 2)
+			for i := range errs {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					ctx, span := tracer.Start(context.Background(), "request.cycle")
+					defer span.End()
+					_, _, errs[i] = client.GetBytes(ctx, server.URL)
+				}(i)
+			}
+			wg.Wait()
+
+			exhausted := 0
+			for _, err := range errs {
+				if errors.Is(err, ErrBodyMemoryExhausted) {
+					exhausted++
+				} else if err != nil {
+					t.Errorf("GetBytes() error = %v", err)
+				}
+			}
+
+			var maxWait int64
+			for _, s := range recorder.Ended() {
+				for _, attr := range s.Attributes() {
+					if attr.Key == "http.body.memory_wait_ms" && attr.Value.AsInt64() > maxWait {
+						maxWait = attr.Value.AsInt64()
+					}
+				}
+			}
+
+			if tt.failFast {
+				if exhausted != 1 {
+					t.Errorf("Requests failing with ErrBodyMemoryExhausted = %d, expected 1", exhausted)
+				}
+				return
+			}
+			if exhausted != 0 {
+				t.Errorf("Requests failing with ErrBodyMemoryExhausted = %d, expected 0", exhausted)
+			}
+			if maxWait < 200 {
+				t.Errorf("Longest http.body.memory_wait_ms = %d, expected the second read to wait for the first", maxWait)
+			}
+		})
+	}
+}
+
+func TestClient_ReadBody_CaptureResponseTrailers(t *testing.T) {
+	// Create a test server that sets a trailer after the body
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+		w.Header().Set("Grpc-Status", "0")
+	}))
+	defer server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{
+		Timeout:                 5 * time.Second,
+		CaptureResponseTrailers: []string{"Grpc-Status", "Grpc-Message"},
+	}, logger, tracer)
+	defer client.Close()
+
+	ctx, span := tracer.Start(context.Background(), "request.cycle")
+	if _, _, err := client.GetBytes(ctx, server.URL); err != nil {
+		t.Fatalf("GetBytes() error = %v", err)
+	}
+	span.End()
+
+	// Check the captured trailers on the cycle span
+	attrs := map[string]string{}
+	for _, s := range recorder.Ended() {
+		if s.Name() != "request.cycle" {
+			continue
+		}
+		for _, attr := range s.Attributes() {
+			attrs[string(attr.Key)] = attr.Value.Emit()
+		}
+	}
+	if attrs["http.response.trailer.grpc-status"] != "0" {
+		t.Errorf("http.response.trailer.grpc-status = %q, expected %q", attrs["http.response.trailer.grpc-status"], "0")
+	}
+	if _, ok := attrs["http.response.trailer.grpc-message"]; ok {
+		t.Error("Expected absent trailer to be skipped")
+	}
+}
+
+func TestClient_Do_Headers(t *testing.T) {
+	// Create a test server that captures the request
+	var method, custom string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, custom = r.Method, r.Header.Get("X-Custom")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a no-op tracer
+	tracer := noop.NewTracerProvider().Tracer("test")
+
+	client := New(Config{Timeout: 5 * time.Second}, logger, tracer)
+	defer client.Close()
+
+	resp, err := client.Do(context.Background(), http.MethodDelete, server.URL, http.Header{"x-custom": {"value"}})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if method != http.MethodDelete {
+		t.Errorf("Do() method = %s, expected %s", method, http.MethodDelete)
+	}
+	if custom != "value" {
+		t.Errorf("Do() X-Custom header = %q, expected %q", custom, "value")
+	}
+}
+
+func TestClient_ReadBody_VerifyContentLength(t *testing.T) {
+	// Create a test server that declares more bytes than it sends
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("truncated"))
+	}))
+	defer server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{
+		Timeout:             5 * time.Second,
+		VerifyContentLength: true,
+	}, logger, tracer)
+	defer client.Close()
+
+	ctx, span := tracer.Start(context.Background(), "request.cycle")
+	_, _, _ = client.GetBytes(ctx, server.URL)
+	span.End()
+
+	// Check the mismatch on the cycle span
+	for _, s := range recorder.Ended() {
+		if s.Name() != "request.cycle" {
+			continue
+		}
+		attrs := map[string]string{}
+		for _, attr := range s.Attributes() {
+			attrs[string(attr.Key)] = attr.Value.Emit()
+		}
+		if attrs["http.response.length_mismatch"] != "true" {
+			t.Errorf("http.response.length_mismatch = %q, expected true", attrs["http.response.length_mismatch"])
+		}
+		if attrs["http.response.declared_length"] != "100" {
+			t.Errorf("http.response.declared_length = %q, expected 100", attrs["http.response.declared_length"])
+		}
+		if attrs["http.response.actual_length"] != "9" {
+			t.Errorf("http.response.actual_length = %q, expected 9", attrs["http.response.actual_length"])
+		}
+		if s.Status().Code != codes.Error {
+			t.Errorf("request.cycle status = %v, expected Error", s.Status().Code)
+		}
+	}
+}
+
+func TestClient_ReadBody_VerifyContentLength_Match(t *testing.T) {
+	// Create a test server with an accurate and an unknown length
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked" {
+			w.(http.Flusher).Flush()
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{
+		Timeout:             5 * time.Second,
+		VerifyContentLength: true,
+	}, logger, tracer)
+	defer client.Close()
+
+	for _, path := range []string{"/", "/chunked"} {
+		ctx, span := tracer.Start(context.Background(), "request.cycle")
+		if _, _, err := client.GetBytes(ctx, server.URL+path); err != nil {
+			t.Fatalf("GetBytes(%s) error = %v", path, err)
+		}
+		span.End()
+	}
+
+	for _, s := range recorder.Ended() {
+		for _, attr := range s.Attributes() {
+			if attr.Key == "http.response.length_mismatch" {
+				t.Errorf("%s span recorded a length mismatch, expected none", s.Name())
+			}
+		}
+	}
+}
+
+func TestClient_Post_CompressRequestBody(t *testing.T) {
+	payload := strings.Repeat(`{"event":"probe"}`, 100)
+
+	// Create a test server that decompresses the body
+	var received string
+	var encoding string
+	var contentLength int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding = r.Header.Get("Content-Encoding")
+		contentLength = r.ContentLength
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, err := io.ReadAll(zr)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{
+		Timeout:             5 * time.Second,
+		CompressRequestBody: true,
+	}, logger, tracer)
+	defer client.Close()
+
+	resp, err := client.Post(context.Background(), server.URL, "application/json", []byte(payload))
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Post() status = %d, expected %d", resp.StatusCode, http.StatusOK)
+	}
+	if encoding != "gzip" {
+		t.Errorf("Content-Encoding = %q, expected %q", encoding, "gzip")
+	}
+	if received != payload {
+		t.Errorf("Decompressed body length = %d, expected %d", len(received), len(payload))
+	}
+
+	// Check the size attributes on the request span
+	attrs := map[string]int64{}
+	for _, s := range recorder.Ended() {
+		if s.Name() != "http.post" {
+			continue
+		}
+		for _, attr := range s.Attributes() {
+			attrs[string(attr.Key)] = attr.Value.AsInt64()
+		}
+	}
+	if attrs["http.request.uncompressed_size"] != int64(len(payload)) {
+		t.Errorf("http.request.uncompressed_size = %d, expected %d", attrs["http.request.uncompressed_size"], len(payload))
+	}
+	if attrs["http.request.body_size"] != contentLength {
+		t.Errorf("http.request.body_size = %d, expected Content-Length %d", attrs["http.request.body_size"], contentLength)
+	}
+	if contentLength >= int64(len(payload)) {
+		t.Errorf("Content-Length = %d, expected less than uncompressed size %d", contentLength, len(payload))
+	}
+}
+
+func TestClient_Get_FailureInjection(t *testing.T) {
+	// Create a test server that counts dispatched requests
+	var dispatched int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dispatched++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{
+		Timeout:              5 * time.Second,
+		FailureInjectionRate: 1.0,
+	}, logger, tracer)
+	defer client.Close()
+
+	for i := 0; i < 5; i++ {
+		resp, err := client.Get(context.Background(), server.URL)
+		if !errors.Is(err, ErrInjectedFailure) {
+			t.Errorf("Get() error = %v, expected ErrInjectedFailure", err)
+		}
+		if resp != nil {
+			t.Error("Get() returned a response for an injected failure")
+		}
+	}
+
+	if dispatched != 0 {
+		t.Errorf("Server received %d requests, expected none", dispatched)
+	}
+
+	// Check that every request span carries the injected marker
+	spans := recorder.Ended()
+	if len(spans) != 5 {
+		t.Fatalf("Recorded %d spans, expected 5", len(spans))
+	}
+	for _, s := range spans {
+		injected := false
+		for _, attr := range s.Attributes() {
+			if attr.Key == "error.injected" && attr.Value.AsBool() {
+				injected = true
+			}
+		}
+		if !injected {
+			t.Errorf("Span %s missing error.injected=true", s.Name())
+		}
+		if s.Status().Code != codes.Error {
+			t.Errorf("Span %s status = %v, expected %v", s.Name(), s.Status().Code, codes.Error)
+		}
+	}
+}
+
+func TestClient_Get_BearerTokenFile(t *testing.T) {
+	// Create a test server that records the Authorization header
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a no-op tracer
+	tracer := noop.NewTracerProvider().Tracer("test")
+
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("first-token\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write token file: %v", err)
+	}
+
+	fakeClock := clock.NewFake(time.Date(2024, 1, 15, 10, 30, 45, 0, time.UTC))
+	client := New(Config{
+		Timeout:         5 * time.Second,
+		BearerTokenFile: path,
+		BearerTokenTTL:  30 * time.Second,
+		Clock:           fakeClock,
+	}, logger, tracer)
+	defer client.Close()
+
+	get := func() {
+		t.Helper()
+		resp, err := client.Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	get()
+	if authorization != "Bearer first-token" {
+		t.Errorf("Authorization = %q, expected %q", authorization, "Bearer first-token")
+	}
+
+	// Rotate the token; the cached token is used until the TTL expires
+	if err := os.WriteFile(path, []byte("second-token\n"), 0o600); err != nil {
+		t.Fatalf("Failed to rewrite token file: %v", err)
+	}
+	fakeClock.Advance(10 * time.Second)
+	get()
+	if authorization != "Bearer first-token" {
+		t.Errorf("Authorization before TTL = %q, expected %q", authorization, "Bearer first-token")
+	}
+
+	fakeClock.Advance(30 * time.Second)
+	get()
+	if authorization != "Bearer second-token" {
+		t.Errorf("Authorization after TTL = %q, expected %q", authorization, "Bearer second-token")
+	}
+}
+
+func TestClient_Get_CaptureResponseBaggage(t *testing.T) {
+	// Create a test server echoing correlation data and recording baggage
+	var baggageHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		baggageHeader = r.Header.Get("Baggage")
+		w.Header().Set("X-Trace-Context", "hop-42")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{
+		Timeout:                5 * time.Second,
+		CaptureResponseBaggage: []string{"X-Trace-Context"},
+	}, logger, tracer)
+	defer client.Close()
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	// Check the captured header on the request spans
+	captured := 0
+	for _, s := range recorder.Ended() {
+		if s.Name() != "http.get" {
+			continue
+		}
+		for _, attr := range s.Attributes() {
+			if attr.Key == "http.response.header.x-trace-context" && attr.Value.AsString() == "hop-42" {
+				captured++
+			}
+		}
+	}
+	if captured != 2 {
+		t.Errorf("Captured header on %d spans, expected 2", captured)
+	}
+
+	// Check the value was carried as baggage on the second request
+	if baggageHeader != "x-trace-context=hop-42" {
+		t.Errorf("Baggage header = %q, expected %q", baggageHeader, "x-trace-context=hop-42")
+	}
+}
+
+func TestClient_Get_FinalURL(t *testing.T) {
+	// Create a test server redirecting /old to /new
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusFound)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{Timeout: 5 * time.Second}, logger, tracer)
+	defer client.Close()
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/old", server.URL + "/new"},
+		{"/new", ""},
+	}
+
+	for _, tt := range tests {
+		resp, err := client.Get(context.Background(), server.URL+tt.path)
+		if err != nil {
+			t.Fatalf("Get(%s) error = %v", tt.path, err)
+		}
+		resp.Body.Close()
+
+		spans := recorder.Ended()
+		finalURL := ""
+		for _, attr := range spans[len(spans)-1].Attributes() {
+			if attr.Key == "http.response.final_url" {
+				finalURL = attr.Value.AsString()
+			}
+		}
+		if finalURL != tt.expected {
+			t.Errorf("http.response.final_url for %s = %q, expected %q", tt.path, finalURL, tt.expected)
+		}
+	}
+}
+
+func TestClient_Get_DNSCache(t *testing.T) {
+	// Create a test server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	url := strings.Replace(server.URL, "127.0.0.1", "probe.test", 1)
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	fakeClock := clock.NewFake(time.Date(2024, 1, 15, 10, 30, 45, 0, time.UTC))
+	client := New(Config{
+		Timeout:     5 * time.Second,
+		DNSCacheTTL: time.Minute,
+		Clock:       fakeClock,
+	}, logger, tracer)
+	defer client.Close()
+
+	// Count real resolutions
+	lookups := 0
+	client.httpClient.Transport.(*instrumentedTransport).dns.lookup = func(ctx context.Context, host string) ([]net.IP, error) {
+		lookups++
+		return []net.IP{net.ParseIP("127.0.0.1")}, nil
+	}
+
+	get := func() {
+		t.Helper()
+		resp, err := client.Get(context.Background(), url)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	get()
+	get()
+	if lookups != 1 {
+		t.Errorf("Resolutions within TTL = %d, expected 1", lookups)
+	}
+
+	// Entries expire after the TTL
+	fakeClock.Advance(2 * time.Minute)
+	get()
+	if lookups != 2 {
+		t.Errorf("Resolutions after TTL = %d, expected 2", lookups)
+	}
+
+	// Check the cache attribute on each dns.resolve span
+	var results []string
+	for _, s := range recorder.Ended() {
+		if s.Name() != "dns.resolve" {
+			continue
+		}
+		for _, attr := range s.Attributes() {
+			if attr.Key == "dns.cache" {
+				results = append(results, attr.Value.AsString())
+			}
+		}
+	}
+	expected := []string{"miss", "hit", "miss"}
+	if strings.Join(results, ",") != strings.Join(expected, ",") {
+		t.Errorf("dns.cache attributes = %v, expected %v", results, expected)
+	}
+}
+
+func TestResolvingDial_NoAddresses(t *testing.T) {
+	dial := resolvingDial(&net.Dialer{}, func(ctx context.Context, host string) ([]net.IP, error) {
+		return nil, nil
+	})
+
+	conn, err := dial(context.Background(), "tcp", "probe.test:80")
+	if conn != nil {
+		conn.Close()
+		t.Error("Expected no connection for a host without addresses")
+	}
+	if err == nil || !strings.Contains(err.Error(), "no addresses for host") {
+		t.Errorf("dial() error = %v, expected a no addresses error", err)
+	}
+}
+
+func TestClient_Get_MaxConcurrentDNS(t *testing.T) {
+	// Create a test server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{
+		Timeout:          5 * time.Second,
+		MaxConcurrentDNS: 1,
+	}, logger, tracer)
+	defer client.Close()
+
+	// Slow down lookups and track how many overlap
+	var (
+		mu       sync.Mutex
+		inFlight int
+		maxSeen  int
+	)
+	client.httpClient.Transport.(*instrumentedTransport).dnsLimit.next = func(ctx context.Context, host string) ([]net.IP, error) {
+		mu.Lock()
+		inFlight++
+		maxSeen = max(maxSeen, inFlight)
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return []net.IP{net.ParseIP("127.0.0.1")}, nil
+	}
+
+	const requests = 5
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(context.Background(), server.URL)
+			if err != nil {
+				t.Errorf("Get() error = %v", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if maxSeen != 1 {
+		t.Errorf("Concurrent lookups = %d, expected 1", maxSeen)
+	}
+
+	// Lookups that queued for the slot record their wait
+	waited := 0
+	for _, s := range recorder.Ended() {
+		if s.Name() != "dns.resolve" {
+			continue
+		}
+		for _, attr := range s.Attributes() {
+			if attr.Key == "dns.queue_wait_ms" && attr.Value.AsInt64() > 0 {
+				waited++
+			}
+		}
+	}
+	if waited == 0 {
+		t.Error("No dns.resolve span recorded dns.queue_wait_ms, expected queued lookups")
+	}
+}
+
+func TestClient_Get_MaxConcurrentDNS_Dial(t *testing.T) {
+	// Create a test server reached by host name, so dials resolve it
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	// Skip dns.resolve spans so only dial lookups remain
+	ratio := 0.0
+	client := New(Config{
+		Timeout:               5 * time.Second,
+		MaxConcurrentDNS:      1,
+		DetailSpanSampleRatio: &ratio,
+	}, zap.NewNop(), noop.NewTracerProvider().Tracer("test"))
+	defer client.Close()
+
+	// Slow down lookups and track how many overlap
+	var (
+		mu       sync.Mutex
+		lookups  int
+		inFlight int
+		maxSeen  int
+	)
+	limiter := client.httpClient.Transport.(*instrumentedTransport).dnsLimit
+	limiter.next = func(ctx context.Context, host string) ([]net.IP, error) {
+		mu.Lock()
+		lookups++
+		inFlight++
+		maxSeen = max(maxSeen, inFlight)
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return []net.IP{net.ParseIP("127.0.0.1")}, nil
+	}
+
+	const requests = 5
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(context.Background(), "http://tracer-test.invalid:"+port)
+			if err != nil {
+				t.Errorf("Get() error = %v", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	// Dials the transport abandoned for a reused connection may still be
+	// queued; the limiter hands out slots in order, so taking one waits
+	// for them to finish
+	if err := limiter.sem.Acquire(context.Background(), 1); err != nil {
+		t.Fatalf("Failed to acquire a DNS lookup slot: %v", err)
+	}
+	limiter.sem.Release(1)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if lookups == 0 {
+		t.Fatal("Dials made no lookups through the limiter")
+	}
+	if maxSeen != 1 {
+		t.Errorf("Concurrent dial lookups = %d, expected 1", maxSeen)
+	}
+}
+
+func TestClient_Get_DetailSpanSampleRatio(t *testing.T) {
+	// Create a test server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	ratio := 0.0
+	client := New(Config{
+		Timeout:               5 * time.Second,
+		DetailSpanSampleRatio: &ratio,
+	}, zap.NewNop(), tracer)
+	defer client.Close()
+
+	for i := 0; i < 5; i++ {
+		resp, err := client.Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	// Check that only the request spans were recorded
+	requests := 0
+	for _, s := range recorder.Ended() {
+		switch s.Name() {
+		case "http.get":
+			requests++
+		case "http.transport", "dns.resolve", "tcp.connect":
+			t.Errorf("Unexpected %s span with DetailSpanSampleRatio 0", s.Name())
+		}
+	}
+	if requests != 5 {
+		t.Errorf("http.get spans = %d, expected 5", requests)
+	}
+}
+
+func TestClient_Get_ScrubQueryParams(t *testing.T) {
+	// Create a test server that records the query it received
+	var receivedQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedQuery = r.URL.RawQuery
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		config   Config
+		expected string
+	}{
+		{
+			name:     "named parameters",
+			config:   Config{ScrubQueryParams: []string{"token"}},
+			expected: server.URL + "/data?page=2&token=REDACTED",
+		},
+		{
+			name:     "all parameters",
+			config:   Config{ScrubAllQueryParams: true},
+			expected: server.URL + "/data",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a test logger
+			core, recorded := observer.New(zapcore.InfoLevel)
+			logger := zap.New(core)
+
+			// Create a recording tracer
+			recorder := tracetest.NewSpanRecorder()
+			tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+			tt.config.Timeout = 5 * time.Second
+			client := New(tt.config, logger, tracer)
+			defer client.Close()
+
+			resp, err := client.Get(context.Background(), server.URL+"/data?token=s3cret&page=2")
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			resp.Body.Close()
+
+			// The real query is sent on the wire
+			if receivedQuery != "token=s3cret&page=2" {
+				t.Errorf("Server received query %q, expected %q", receivedQuery, "token=s3cret&page=2")
+			}
+
+			// Only the scrubbed URL is recorded
+			for _, s := range recorder.Ended() {
+				for _, attr := range s.Attributes() {
+					if attr.Key == "http.url" && attr.Value.AsString() != tt.expected {
+						t.Errorf("Span %s http.url = %q, expected %q", s.Name(), attr.Value.AsString(), tt.expected)
+					}
+				}
+			}
+			for _, entry := range recorded.All() {
+				if url, ok := entry.ContextMap()["url"]; ok && strings.Contains(url.(string), "s3cret") {
+					t.Errorf("Log %q contains the secret: %s", entry.Message, url)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_GetStream(t *testing.T) {
+	const size = 200 * 1024
+
+	// Create a test server streaming a large body
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(make([]byte, size))
+	}))
+	defer server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{
+		Timeout:             5 * time.Second,
+		StreamProgressBytes: 50 * 1024,
+	}, logger, tracer)
+	defer client.Close()
+
+	var chunked int
+	resp, total, err := client.GetStream(context.Background(), server.URL, func(n int) {
+		chunked += n
+	})
+	if err != nil {
+		t.Fatalf("GetStream() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GetStream() status = %d, expected %d", resp.StatusCode, http.StatusOK)
+	}
+	if total != size || chunked != size {
+		t.Errorf("GetStream() total = %d, callback total = %d, expected %d", total, chunked, size)
+	}
+
+	// Check the stream span's progress events and total
+	var stream sdktrace.ReadOnlySpan
+	for _, s := range recorder.Ended() {
+		if s.Name() == "http.stream" {
+			stream = s
+		}
+	}
+	if stream == nil {
+		t.Fatal("Expected an ended http.stream span")
+	}
+	if events := len(stream.Events()); events != 4 {
+		t.Errorf("http.stream recorded %d progress events, expected 4", events)
+	}
+	for _, attr := range stream.Attributes() {
+		if attr.Key == "http.response.body_size" && attr.Value.AsInt64() != size {
+			t.Errorf("http.response.body_size = %d, expected %d", attr.Value.AsInt64(), size)
+		}
+	}
+}
+
+func TestClient_Get_MinTLSVersion(t *testing.T) {
+	// Create a TLS 1.1-only test server
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{
+		MinVersion: tls.VersionTLS10,
+		MaxVersion: tls.VersionTLS11,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a no-op tracer
+	tracer := noop.NewTracerProvider().Tracer("test")
+
+	config := Config{
+		Timeout:       5 * time.Second,
+		MinTLSVersion: "1.2",
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	client := New(config, logger, tracer)
+	defer client.Close()
+
+	_, err := client.Get(context.Background(), server.URL)
+	if err == nil {
+		t.Fatal("Get() error = nil, expected a TLS handshake failure")
+	}
+	if !strings.Contains(err.Error(), "protocol version") {
+		t.Errorf("Get() error = %v, expected a protocol version error", err)
+	}
+}
+
+func TestNew_InvalidTLSSettings(t *testing.T) {
+	// Create a TLS 1.2-only test server
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+
+	// Create a test logger with observer
+	core, recorded := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Skip Validate, as a careless caller would
+	client := New(Config{
+		Timeout:       5 * time.Second,
+		MinTLSVersion: "1.4",
+	}, logger, noop.NewTracerProvider().Tracer("test"))
+	defer client.Close()
+
+	if recorded.FilterMessage("Invalid TLS settings, requiring TLS 1.3").Len() != 1 {
+		t.Error("Expected the invalid TLS settings to be logged")
+	}
+	if _, err := client.Get(context.Background(), server.URL); err == nil {
+		t.Error("Get() error = nil, expected TLS 1.3 to be required")
+	}
+}
+
+func TestClient_Get_TLSSessionResumption(t *testing.T) {
+	// Create a TLS test server that closes each connection so the second
+	// request needs a new handshake
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Connection", "close")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{Timeout: 5 * time.Second}, logger, tracer)
+	defer client.Close()
+
+	// Trust the test server's certificate
+	base := client.httpClient.Transport.(*instrumentedTransport).base.(*http.Transport)
+	base.TLSClientConfig.RootCAs = server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	var resumed []bool
+	for _, s := range recorder.Ended() {
+		if s.Name() != "http.get" {
+			continue
+		}
+		for _, attr := range s.Attributes() {
+			if attr.Key == "tls.resumed" {
+				resumed = append(resumed, attr.Value.AsBool())
+			}
+		}
+	}
+	if len(resumed) != 2 || resumed[0] || !resumed[1] {
+		t.Errorf("tls.resumed = %v, expected [false true]", resumed)
+	}
+}
+
+func TestConfig_Validate_TLS(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"no TLS options", Config{}, false},
+		{"valid options", Config{MinTLSVersion: "1.2", CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}}, false},
+		{"unknown version", Config{MinTLSVersion: "1.4"}, true},
+		{"unknown cipher suite", Config{CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
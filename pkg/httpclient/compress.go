@@ -0,0 +1,19 @@
+package httpclient
+
+import (
+	"bytes"
+	"compress/gzip"
+)
+
+// gzipBody returns body compressed with gzip
+func gzipBody(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,145 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+)
+
+// ConnStats holds connection pool counts for a single host
+type ConnStats struct {
+	// Idle counts HTTP/1 connections returned to the pool
+	Idle int
+	// Active counts connections with a request in flight
+	Active int
+}
+
+// hostConns holds the tracked connections for a single host
+type hostConns struct {
+	idle int
+	// streams counts in-flight requests per connection; HTTP/2 connections
+	// carry several at once
+	streams map[net.Conn]int
+}
+
+// connTracker tracks idle and active connections per host using
+// httptrace callbacks. A request holds its connection from GotConn until
+// its response body is closed, which covers HTTP/2 and connections that
+// are closed instead of pooled. Counts are approximate: connections closed
+// by the transport's idle timeout are not observed.
+type connTracker struct {
+	mu    sync.Mutex
+	hosts map[string]*hostConns
+}
+
+// newConnTracker creates an empty connection tracker
+func newConnTracker() *connTracker {
+	return &connTracker{hosts: make(map[string]*hostConns)}
+}
+
+// connUse is a single request's hold on a connection
+type connUse struct {
+	ct   *connTracker
+	host string
+	// conn is the connection the request was given, guarded by ct.mu
+	conn net.Conn
+}
+
+// track returns a context whose client trace updates the counters for
+// host, and the request's connUse to finish once the round trip returns
+func (ct *connTracker) track(ctx context.Context, host string) (context.Context, *connUse) {
+	use := &connUse{ct: ct, host: host}
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			ct.update(host, func(h *hostConns) {
+				// The transport retried the request on another connection
+				if use.conn != nil {
+					h.release(use.conn)
+				}
+				if info.Reused && info.WasIdle && h.idle > 0 {
+					h.idle--
+				}
+				use.conn = info.Conn
+				h.streams[info.Conn]++
+			})
+		},
+		PutIdleConn: func(err error) {
+			// A non-nil error means the connection was closed instead of pooled
+			if err == nil {
+				ct.update(host, func(h *hostConns) { h.idle++ })
+			}
+		},
+	})
+	return ctx, use
+}
+
+// finish releases the connection once the request is done with it: right
+// away when the round trip failed, otherwise when the body is closed
+func (u *connUse) finish(resp *http.Response, err error) *http.Response {
+	if err != nil || resp == nil {
+		u.release()
+		return resp
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: sync.OnceFunc(u.release)}
+	return resp
+}
+
+// release drops the request from its connection's in-flight count
+func (u *connUse) release() {
+	u.ct.update(u.host, func(h *hostConns) {
+		if u.conn != nil {
+			h.release(u.conn)
+			u.conn = nil
+		}
+	})
+}
+
+// release drops one in-flight request from conn
+func (h *hostConns) release(conn net.Conn) {
+	if h.streams[conn] <= 1 {
+		delete(h.streams, conn)
+		return
+	}
+	h.streams[conn]--
+}
+
+// update applies fn to the counters for host under the lock
+func (ct *connTracker) update(host string, fn func(*hostConns)) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	h, ok := ct.hosts[host]
+	if !ok {
+		h = &hostConns{streams: make(map[net.Conn]int)}
+		ct.hosts[host] = h
+	}
+	fn(h)
+}
+
+// snapshot returns a copy of the current counters keyed by host
+func (ct *connTracker) snapshot() map[string]ConnStats {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	result := make(map[string]ConnStats, len(ct.hosts))
+	for host, h := range ct.hosts {
+		result[host] = ConnStats{Idle: h.idle, Active: len(h.streams)}
+	}
+	return result
+}
+
+// releasingBody calls release when the response body is closed
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+// Close closes the body and releases its connection
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
@@ -0,0 +1,96 @@
+//go:build linux
+
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// blackholeAddr returns a loopback address whose accept queue is full, so
+// new connections hang until the dial times out
+func blackholeAddr(t *testing.T) string {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_STREAM, 0)
+	if err != nil {
+		t.Fatalf("Socket() error = %v", err)
+	}
+	t.Cleanup(func() { _ = syscall.Close(fd) })
+	if err := syscall.Bind(fd, &syscall.SockaddrInet4{Addr: [4]byte{127, 0, 0, 1}}); err != nil {
+		t.Fatalf("Bind() error = %v", err)
+	}
+	if err := syscall.Listen(fd, 0); err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	sa, err := syscall.Getsockname(fd)
+	if err != nil {
+		t.Fatalf("Getsockname() error = %v", err)
+	}
+	addr := fmt.Sprintf("127.0.0.1:%d", sa.(*syscall.SockaddrInet4).Port)
+
+	// Fill the accept queue without ever accepting
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return addr
+}
+
+func TestClient_Get_DialTimeout(t *testing.T) {
+	addr := blackholeAddr(t)
+
+	// Create a test logger with observer
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{
+		Timeout:     10 * time.Second,
+		DialTimeout: 100 * time.Millisecond,
+		KeepAlive:   15 * time.Second,
+	}, logger, tracer)
+	defer client.Close()
+
+	start := time.Now()
+	_, err := client.Get(context.Background(), "http://"+addr)
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatal("Get() error = nil, expected a dial timeout")
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("Get() took %s, expected to fail promptly after the dial timeout", elapsed)
+	}
+	if category := ErrorCategory(err); category != failureTimeout {
+		t.Errorf("ErrorCategory() = %q, expected %q", category, failureTimeout)
+	}
+
+	// Check that the dial timeout was recorded on the request span
+	for _, s := range recorder.Ended() {
+		if s.Name() != "http.get" {
+			continue
+		}
+		found := false
+		for _, attr := range s.Attributes() {
+			if attr.Key == "net.dial.timeout_ms" && attr.Value.AsInt64() == 100 {
+				found = true
+			}
+		}
+		if !found {
+			t.Error("Expected http.get span to have net.dial.timeout_ms=100")
+		}
+	}
+}
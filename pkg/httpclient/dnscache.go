@@ -0,0 +1,105 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"tracer-test/pkg/clock"
+)
+
+// lookupFunc resolves a hostname to IP addresses
+type lookupFunc func(ctx context.Context, host string) ([]net.IP, error)
+
+// lookupIP resolves host with the default resolver
+func lookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	return net.DefaultResolver.LookupIP(ctx, "ip", host)
+}
+
+// dnsEntry is a cached resolution
+type dnsEntry struct {
+	ips     []net.IP
+	expires time.Time
+}
+
+// dnsCache caches successful DNS resolutions for ttl. Failed lookups are
+// never cached.
+type dnsCache struct {
+	ttl    time.Duration
+	clock  clock.Clock
+	lookup lookupFunc
+
+	mu      sync.Mutex
+	entries map[string]dnsEntry
+}
+
+// newDNSCache creates a DNS cache resolving misses with lookup
+func newDNSCache(ttl time.Duration, clk clock.Clock, lookup lookupFunc) *dnsCache {
+	return &dnsCache{
+		ttl:     ttl,
+		clock:   clk,
+		lookup:  lookup,
+		entries: make(map[string]dnsEntry),
+	}
+}
+
+// resolve returns the addresses for host and whether they came from the cache
+func (c *dnsCache) resolve(ctx context.Context, host string) ([]net.IP, bool, error) {
+	now := c.clock.Now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[host]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.ips, true, nil
+	}
+
+	ips, err := c.lookup(ctx, host)
+	if err != nil {
+		return nil, false, err
+	}
+
+	c.mu.Lock()
+	c.entries[host] = dnsEntry{ips: ips, expires: now.Add(c.ttl)}
+	c.mu.Unlock()
+	return ips, false, nil
+}
+
+// dialContext dials addr using cached resolutions, trying each address in turn
+func (c *dnsCache) dialContext(dialer *net.Dialer) dialFunc {
+	return resolvingDial(dialer, func(ctx context.Context, host string) ([]net.IP, error) {
+		ips, _, err := c.resolve(ctx, host)
+		return ips, err
+	})
+}
+
+// resolvingDial dials addr after resolving its host with resolve, trying
+// each address in turn. IP addresses are dialed directly.
+func resolvingDial(dialer *net.Dialer, resolve lookupFunc) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return dialer.DialContext(ctx, network, addr)
+		}
+
+		ips, err := resolve(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		if len(ips) == 0 {
+			return nil, &net.DNSError{Err: "no addresses for host", Name: host, IsNotFound: true}
+		}
+
+		var errs []error
+		for _, ip := range ips {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			errs = append(errs, err)
+		}
+		return nil, errors.Join(errs...)
+	}
+}
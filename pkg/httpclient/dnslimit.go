@@ -0,0 +1,62 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"tracer-test/pkg/clock"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/semaphore"
+)
+
+// dnsLimiter bounds the number of DNS lookups in flight at once, covering
+// both the lookups behind dns.resolve spans and those made when dialing
+type dnsLimiter struct {
+	sem   *semaphore.Weighted
+	clock clock.Clock
+	// next performs the lookup once a slot is free
+	next lookupFunc
+}
+
+// newDNSLimiter creates a limiter allowing max concurrent lookups through
+// next
+func newDNSLimiter(max int, clk clock.Clock, next lookupFunc) *dnsLimiter {
+	return &dnsLimiter{sem: semaphore.NewWeighted(int64(max)), clock: clk, next: next}
+}
+
+// lookup resolves host once a slot is free. Time spent waiting for the slot
+// is recorded as dns.queue_wait_ms on the span in ctx.
+func (l *dnsLimiter) lookup(ctx context.Context, host string) ([]net.IP, error) {
+	waited, err := l.acquire(ctx)
+	if waited > 0 {
+		trace.SpanFromContext(ctx).SetAttributes(attribute.Int64("dns.queue_wait_ms", waited.Milliseconds()))
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer l.release()
+	return l.next(ctx, host)
+}
+
+// acquire takes a lookup slot, waiting for one to free up if necessary, and
+// returns how long it waited. The caller must release the slot on success.
+func (l *dnsLimiter) acquire(ctx context.Context) (time.Duration, error) {
+	if l.sem.TryAcquire(1) {
+		return 0, nil
+	}
+
+	start := l.clock.Now()
+	if err := l.sem.Acquire(ctx, 1); err != nil {
+		return l.clock.Now().Sub(start), fmt.Errorf("waiting for DNS lookup slot: %w", err)
+	}
+	return l.clock.Now().Sub(start), nil
+}
+
+// release frees a slot taken by acquire
+func (l *dnsLimiter) release() {
+	l.sem.Release(1)
+}
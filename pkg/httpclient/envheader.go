@@ -0,0 +1,52 @@
+package httpclient
+
+import (
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// envHeaders holds request headers resolved from environment variables
+type envHeaders struct {
+	header http.Header
+	names  []string
+}
+
+// newEnvHeaders resolves the header name to environment variable mapping
+// once. Unset or empty variables are logged and their headers omitted.
+func newEnvHeaders(mapping map[string]string, logger *zap.Logger) *envHeaders {
+	if len(mapping) == 0 {
+		return nil
+	}
+
+	e := &envHeaders{header: make(http.Header, len(mapping))}
+	for name, variable := range mapping {
+		value := os.Getenv(variable)
+		if value == "" {
+			logger.Warn("Environment variable for request header is not set, omitting header",
+				zap.String("header", name),
+				zap.String("env_var", variable))
+			continue
+		}
+		e.header.Set(name, value)
+		e.names = append(e.names, name)
+	}
+	sort.Strings(e.names)
+	return e
+}
+
+// apply sets the resolved headers on req and records their presence, never
+// their values, on span
+func (e *envHeaders) apply(req *http.Request, span trace.Span, record bool) {
+	for _, name := range e.names {
+		req.Header.Set(name, e.header.Get(name))
+		if record {
+			span.SetAttributes(attribute.Bool("http.request.env_header."+strings.ToLower(name), true))
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"syscall"
+)
+
+// Failure types reported by classifyError
+const (
+	failureDNS     = "dns"
+	failureConnect = "connect"
+	failureTLS     = "tls"
+	failureTimeout = "timeout"
+	failureOther   = "other"
+
+	failureSocketTimeout = "socket_timeout"
+)
+
+// classifyError maps a request error to a coarse failure type
+func classifyError(err error) string {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return failureDNS
+	}
+
+	if isSocketTimeout(err) {
+		return failureSocketTimeout
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return failureTimeout
+	}
+
+	var (
+		recordErr    tls.RecordHeaderError
+		verifyErr    *tls.CertificateVerificationError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+	if errors.As(err, &recordErr) || errors.As(err, &verifyErr) || errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+		return failureTLS
+	}
+
+	var opErr *net.OpError
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		(errors.As(err, &opErr) && opErr.Op == "dial") {
+		return failureConnect
+	}
+
+	return failureOther
+}
+
+// ErrorCategory returns the failure type for an error returned by the client,
+// e.g. "dns", "timeout", "socket_timeout" or "body_read_timeout"
+func ErrorCategory(err error) string {
+	if errors.Is(err, ErrBodyReadTimeout) {
+		return "body_read_timeout"
+	}
+	if errors.Is(err, ErrInjectedFailure) {
+		return "injected"
+	}
+	return classifyError(err)
+}
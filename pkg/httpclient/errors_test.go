@@ -0,0 +1,52 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "dns error",
+			err:  &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "invalid.example"}},
+			want: failureDNS,
+		},
+		{
+			name: "deadline exceeded",
+			err:  fmt.Errorf("wrapped: %w", context.DeadlineExceeded),
+			want: failureTimeout,
+		},
+		{
+			name: "unknown certificate authority",
+			err:  fmt.Errorf("wrapped: %w", x509.UnknownAuthorityError{}),
+			want: failureTLS,
+		},
+		{
+			name: "dial error",
+			err:  &net.OpError{Op: "dial", Err: errors.New("network is unreachable")},
+			want: failureConnect,
+		},
+		{
+			name: "other error",
+			err:  errors.New("unsupported protocol scheme"),
+			want: failureOther,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyError(tt.err); got != tt.want {
+				t.Errorf("classifyError() = %s, expected %s", got, tt.want)
+			}
+		})
+	}
+}
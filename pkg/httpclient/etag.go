@@ -0,0 +1,29 @@
+package httpclient
+
+import "sync"
+
+// etagCache remembers the last ETag seen per URL for conditional requests
+type etagCache struct {
+	mu    sync.Mutex
+	etags map[string]string
+}
+
+// newETagCache creates an empty ETag cache
+func newETagCache() *etagCache {
+	return &etagCache{etags: make(map[string]string)}
+}
+
+// get returns the cached ETag for url, if any
+func (c *etagCache) get(url string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	etag, ok := c.etags[url]
+	return etag, ok
+}
+
+// set stores the ETag for url
+func (c *etagCache) set(url, etag string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.etags[url] = etag
+}
@@ -0,0 +1,42 @@
+package httpclient
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newHeader converts a name to value map into canonical request headers
+func newHeader(headers map[string]string) http.Header {
+	if len(headers) == 0 {
+		return nil
+	}
+	header := make(http.Header, len(headers))
+	for name, value := range headers {
+		header.Set(name, value)
+	}
+	return header
+}
+
+// mergeHeaders returns defaults overridden by the headers given for a call
+func mergeHeaders(defaults, header http.Header) http.Header {
+	merged := make(http.Header, len(defaults)+len(header))
+	for name, values := range defaults {
+		merged[name] = values
+	}
+	for name, values := range header {
+		merged[http.CanonicalHeaderKey(name)] = values
+	}
+	return merged
+}
+
+// recordHeaders records the presence of each header as an
+// http.request.header.<name> span attribute. Values are never recorded, as
+// there is no reliable way to tell which headers carry credentials.
+func recordHeaders(span trace.Span, header http.Header) {
+	for name := range header {
+		span.SetAttributes(attribute.Bool("http.request.header."+strings.ToLower(name), true))
+	}
+}
@@ -0,0 +1,124 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"tracer-test/pkg/clock"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// http3HandshakeTimeout bounds the QUIC handshake so servers without HTTP/3
+// fall back quickly
+const http3HandshakeTimeout = 3 * time.Second
+
+// http3RoundTripper sends requests over HTTP/3 (QUIC), falling back to the
+// HTTP/1.1 and HTTP/2 transport when no QUIC connection could be established
+// and a fallback is set
+type http3RoundTripper struct {
+	h3       *http3.Transport
+	fallback http.RoundTripper
+	clock    clock.Clock
+}
+
+// newHTTP3RoundTripper creates an HTTP/3 round tripper. A nil fallback
+// disables falling back.
+func newHTTP3RoundTripper(tlsConfig *tls.Config, fallback http.RoundTripper, clk clock.Clock) *http3RoundTripper {
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	return &http3RoundTripper{
+		h3: &http3.Transport{
+			TLSClientConfig: tlsConfig.Clone(),
+			QUICConfig:      &quic.Config{HandshakeIdleTimeout: http3HandshakeTimeout},
+		},
+		fallback: fallback,
+		clock:    clk,
+	}
+}
+
+// RoundTrip implements http.RoundTripper, recording the QUIC handshake time
+// on the span in the request context
+func (t *http3RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	span := trace.SpanFromContext(req.Context())
+
+	// The handshake hooks run on the dialing goroutine
+	var (
+		mu      sync.Mutex
+		start   time.Time
+		gotConn bool
+	)
+	ctx := httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		GotConn: func(httptrace.GotConnInfo) {
+			mu.Lock()
+			defer mu.Unlock()
+			gotConn = true
+		},
+		TLSHandshakeStart: func() {
+			mu.Lock()
+			defer mu.Unlock()
+			start = t.clock.Now()
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil && !start.IsZero() {
+				span.SetAttributes(attribute.Int64("quic.handshake_ms", t.clock.Now().Sub(start).Milliseconds()))
+			}
+		},
+	})
+
+	resp, err := t.h3.RoundTrip(req.WithContext(ctx))
+	if err == nil || t.fallback == nil {
+		return resp, err
+	}
+
+	// Only fall back when the QUIC dial or handshake failed, so the request
+	// was never sent, and the caller has not given up on it
+	mu.Lock()
+	sent := gotConn
+	mu.Unlock()
+	if sent || req.Context().Err() != nil {
+		return nil, err
+	}
+
+	// Retry over TCP, rewinding the body when there is one
+	retry := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, err
+		}
+		body, bodyErr := req.GetBody()
+		if bodyErr != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+	span.SetAttributes(
+		attribute.Bool("http3.fallback", true),
+		attribute.String("http3.error", err.Error()),
+	)
+	return t.fallback.RoundTrip(retry)
+}
+
+// Close closes the QUIC connections
+func (t *http3RoundTripper) Close() error {
+	return t.h3.Close()
+}
+
+// protocolVersion formats the response protocol as recorded in
+// network.protocol.version, e.g. "HTTP/1.1", "HTTP/2" or "HTTP/3"
+func protocolVersion(resp *http.Response) string {
+	if resp.ProtoMajor >= 2 {
+		return fmt.Sprintf("HTTP/%d", resp.ProtoMajor)
+	}
+	return fmt.Sprintf("HTTP/%d.%d", resp.ProtoMajor, resp.ProtoMinor)
+}
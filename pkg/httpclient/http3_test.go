@@ -0,0 +1,164 @@
+package httpclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tracer-test/pkg/clock"
+
+	"github.com/quic-go/quic-go/http3"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// transportAttributes returns the attributes of the ended http.transport span
+func transportAttributes(recorder *tracetest.SpanRecorder) map[string]string {
+	attrs := map[string]string{}
+	for _, s := range recorder.Ended() {
+		if s.Name() != "http.transport" {
+			continue
+		}
+		for _, attr := range s.Attributes() {
+			attrs[string(attr.Key)] = attr.Value.Emit()
+		}
+	}
+	return attrs
+}
+
+func TestClient_Get_HTTP3(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// Borrow a TLS server's certificate for an HTTP/3 server on UDP
+	certServer := httptest.NewTLSServer(handler)
+	defer certServer.Close()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("UDP unavailable: %v", err)
+	}
+	h3Server := &http3.Server{
+		Handler:   handler,
+		TLSConfig: http3.ConfigureTLSConfig(certServer.TLS),
+	}
+	go func() { _ = h3Server.Serve(conn) }()
+	defer h3Server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{
+		Timeout:              5 * time.Second,
+		EnableHTTP3:          true,
+		DisableHTTP3Fallback: true,
+	}, logger, tracer)
+	defer client.Close()
+
+	// Trust the test server's certificate
+	client.http3.h3.TLSClientConfig.RootCAs = certServer.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+
+	resp, err := client.Get(context.Background(), "https://"+conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	attrs := transportAttributes(recorder)
+	if attrs["network.protocol.version"] != "HTTP/3" {
+		t.Errorf("network.protocol.version = %q, expected HTTP/3", attrs["network.protocol.version"])
+	}
+	if _, ok := attrs["quic.handshake_ms"]; !ok {
+		t.Error("Expected quic.handshake_ms on the transport span")
+	}
+}
+
+func TestClient_Get_HTTP3Fallback(t *testing.T) {
+	// Create a TLS test server without HTTP/3
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{
+		Timeout:     10 * time.Second,
+		EnableHTTP3: true,
+	}, logger, tracer)
+	defer client.Close()
+
+	// Trust the test server's certificate on both transports
+	rootCAs := server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	client.http3.h3.TLSClientConfig.RootCAs = rootCAs
+	client.http3.fallback.(*http.Transport).TLSClientConfig.RootCAs = rootCAs
+
+	resp, err := client.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v, expected fallback to succeed", err)
+	}
+	resp.Body.Close()
+
+	attrs := transportAttributes(recorder)
+	if attrs["http3.fallback"] != "true" {
+		t.Errorf("http3.fallback = %q, expected true", attrs["http3.fallback"])
+	}
+	if attrs["network.protocol.version"] != "HTTP/1.1" {
+		t.Errorf("network.protocol.version = %q, expected HTTP/1.1", attrs["network.protocol.version"])
+	}
+}
+
+// countingTransport counts the requests it receives
+type countingTransport struct {
+	requests int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.requests++
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+}
+
+func TestHTTP3RoundTripper_NoFallbackWhenCanceled(t *testing.T) {
+	// Nothing listens for QUIC on the test server's UDP port
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	fallback := &countingTransport{}
+	rt := newHTTP3RoundTripper(nil, fallback, clock.Real{})
+	defer rt.Close()
+
+	// Give up long before the QUIC handshake times out
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, http.NoBody)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+
+	if _, err := rt.RoundTrip(req); err == nil {
+		t.Fatal("RoundTrip() error = nil, expected the context deadline")
+	}
+	if fallback.requests != 0 {
+		t.Errorf("Fallback requests = %d, expected 0 for a canceled request", fallback.requests)
+	}
+}
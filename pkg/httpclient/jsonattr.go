@@ -0,0 +1,93 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// lookupJSONPath resolves a JSONPath-ish expression such as ".status",
+// "build.version" or ".items[0].id" against a decoded JSON document
+func lookupJSONPath(doc interface{}, path string) (interface{}, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return doc, true
+	}
+
+	current := doc
+	for _, segment := range strings.Split(path, ".") {
+		key, indexes, ok := splitIndexes(segment)
+		if !ok {
+			return nil, false
+		}
+
+		if key != "" {
+			object, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if current, ok = object[key]; !ok {
+				return nil, false
+			}
+		}
+
+		for _, index := range indexes {
+			array, ok := current.([]interface{})
+			if !ok || index < 0 || index >= len(array) {
+				return nil, false
+			}
+			current = array[index]
+		}
+	}
+	return current, true
+}
+
+// splitIndexes splits a path segment like "items[0][1]" into its key and indexes
+func splitIndexes(segment string) (string, []int, bool) {
+	open := strings.IndexByte(segment, '[')
+	if open < 0 {
+		return segment, nil, true
+	}
+
+	key := segment[:open]
+	var indexes []int
+	rest := segment[open:]
+	for rest != "" {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end < 0 {
+			return "", nil, false
+		}
+		index, err := strconv.Atoi(rest[1:end])
+		if err != nil {
+			return "", nil, false
+		}
+		indexes = append(indexes, index)
+		rest = rest[end+1:]
+	}
+	return key, indexes, true
+}
+
+// jsonAttribute converts a decoded JSON value into a span attribute
+func jsonAttribute(name string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(name, v)
+	case bool:
+		return attribute.Bool(name, v)
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return attribute.Int64(name, int64(v))
+		}
+		return attribute.Float64(name, v)
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return attribute.String(name, fmt.Sprint(v))
+		}
+		return attribute.String(name, string(encoded))
+	}
+}
@@ -0,0 +1,38 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLookupJSONPath(t *testing.T) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(`{"status":"ok","build":{"version":"1.2.3"},"items":[{"id":7}]}`), &doc); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	tests := []struct {
+		path   string
+		want   interface{}
+		wantOK bool
+	}{
+		{path: ".status", want: "ok", wantOK: true},
+		{path: "$.build.version", want: "1.2.3", wantOK: true},
+		{path: ".items[0].id", want: float64(7), wantOK: true},
+		{path: ".items[1].id", wantOK: false},
+		{path: ".missing", wantOK: false},
+		{path: ".status.nested", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, ok := lookupJSONPath(doc, tt.path)
+			if ok != tt.wantOK {
+				t.Fatalf("lookupJSONPath() ok = %v, expected %v", ok, tt.wantOK)
+			}
+			if ok && got != tt.want {
+				t.Errorf("lookupJSONPath() = %v, expected %v", got, tt.want)
+			}
+		})
+	}
+}
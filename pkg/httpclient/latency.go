@@ -0,0 +1,31 @@
+package httpclient
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// injectedLatency returns the delay to add before a request: the fixed
+// latency plus a uniformly random jitter
+func (c *Client) injectedLatency() time.Duration {
+	delay := c.latency
+	if c.latencyJitter > 0 {
+		delay += rand.N(c.latencyJitter)
+	}
+	return delay
+}
+
+// sleep waits for d or until ctx is done, returning the context's error if
+// it ended first
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,24 @@
+package httpclient
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// noopSpan stands in for request spans when tracing is disabled. Ending it
+// has no effect, unlike ending the caller's span.
+var noopSpan = trace.SpanFromContext(context.Background())
+
+// isNoopTracer reports whether tracer never records spans
+func isNoopTracer(tracer trace.Tracer) bool {
+	_, ok := tracer.(noop.Tracer)
+	return ok
+}
+
+// TracingEnabled reports whether the client was built with a recording
+// tracer. Callers can skip building span attributes when it is false.
+func (c *Client) TracingEnabled() bool {
+	return !c.tracingDisabled
+}
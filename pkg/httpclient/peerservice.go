@@ -0,0 +1,18 @@
+package httpclient
+
+import "context"
+
+// peerServiceKey is the context key for the target's service name
+type peerServiceKey struct{}
+
+// WithPeerService returns a context naming the service being called. It is
+// recorded as peer.service on request spans.
+func WithPeerService(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, peerServiceKey{}, name)
+}
+
+// PeerServiceFromContext returns the peer service name carried by ctx, if any
+func PeerServiceFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(peerServiceKey{}).(string)
+	return name, ok && name != ""
+}
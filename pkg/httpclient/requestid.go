@@ -0,0 +1,20 @@
+package httpclient
+
+import "context"
+
+// RequestIDHeader is the header carrying the request ID to the target
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the context key for request IDs
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the given request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, if any
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok && id != ""
+}
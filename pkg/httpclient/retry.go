@@ -0,0 +1,191 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// defaultRetryBackoff is the delay before the first retry when RetryBackoff
+// is not set
+const defaultRetryBackoff = 100 * time.Millisecond
+
+// maxRetryBackoff caps the delay between retries however many have failed
+const maxRetryBackoff = 30 * time.Second
+
+// maxRetriesLimit is the largest MaxRetries accepted by Validate
+const maxRetriesLimit = 100
+
+// retryDrainBytes bounds how much of a failed attempt's body is read so its
+// connection can be reused
+const retryDrainBytes = 64 << 10
+
+// idempotentMethods are safe to send again after a failed attempt
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+// send sends req, retrying idempotent requests that fail with a network
+// error or a 5xx status up to maxRetries times. Each attempt gets its own
+// child span of span. The last attempt's outcome is returned, and running
+// out of retries is logged as retries_exhausted.
+func (c *Client) send(ctx context.Context, req *http.Request, span trace.Span) (*http.Response, error) {
+	if c.maxRetries <= 0 || !idempotentMethods[req.Method] {
+		return c.httpClient.Do(req)
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.sendAttempt(ctx, req, attempt)
+		retry := c.shouldRetry(ctx, resp, err)
+		delay := c.retryDelay(attempt)
+		if attempt == c.maxRetries || !retry || c.expiresWithin(ctx, delay) {
+			if !c.tracingDisabled {
+				span.SetAttributes(attribute.Int("http.retry.count", attempt))
+			}
+			if attempt == c.maxRetries && retry {
+				c.retriesExhausted(req, span, attempt+1, resp, err)
+			}
+			return resp, err
+		}
+
+		fields := []zap.Field{
+			zap.String("url", c.scrubber.scrubURL(req.URL)),
+			zap.Int("attempt", attempt+1),
+			zap.Duration("backoff", delay),
+		}
+		if err != nil {
+			fields = append(fields, zap.Error(c.scrubber.scrubError(err)))
+		} else {
+			fields = append(fields, zap.Int("status_code", resp.StatusCode))
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, retryDrainBytes))
+			_ = resp.Body.Close()
+		}
+		c.logger.Warn("Retrying HTTP request", fields...)
+
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retriesExhausted reports a request that still failed after its last
+// retry, separately from requests that failed on their only attempt
+func (c *Client) retriesExhausted(req *http.Request, span trace.Span, attempts int, resp *http.Response, err error) {
+	c.exhausted.Add(1)
+
+	var lastError string
+	if err != nil {
+		lastError = c.scrubber.scrubError(err).Error()
+	} else {
+		lastError = fmt.Sprintf("HTTP %d", resp.StatusCode)
+	}
+	c.logger.Error("retries_exhausted",
+		zap.Int("attempts", attempts),
+		zap.String("last_error", lastError),
+		zap.String("url", c.scrubber.scrubURL(req.URL)),
+		zap.String("trace_id", span.SpanContext().TraceID().String()))
+}
+
+// RetriesExhausted returns the number of requests that failed after using
+// up all of their retries
+func (c *Client) RetriesExhausted() int64 {
+	return c.exhausted.Load()
+}
+
+// sendAttempt sends one attempt of req under an http.<method>.attempt span
+// recording the attempt's retry count and outcome
+func (c *Client) sendAttempt(ctx context.Context, req *http.Request, attempt int) (*http.Response, error) {
+	if c.tracingDisabled {
+		return c.httpClient.Do(c.attemptRequest(ctx, req, attempt))
+	}
+
+	ctx, span := c.tracer.Start(ctx, "http."+strings.ToLower(req.Method)+".attempt",
+		trace.WithAttributes(attribute.Int("http.retry.count", attempt)))
+	defer span.End()
+
+	// Let the target see which attempt it is serving
+	attemptReq := c.attemptRequest(ctx, req, attempt)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(attemptReq.Header))
+
+	resp, err := c.httpClient.Do(attemptReq)
+	switch {
+	case err != nil:
+		err = c.scrubber.scrubError(err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case c.IsErrorStatus(resp.StatusCode):
+		span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+		span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", resp.StatusCode))
+	default:
+		span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+		span.SetStatus(codes.Ok, "")
+	}
+	return resp, err
+}
+
+// attemptRequest returns a copy of req bound to ctx, with its body rewound
+// after the first attempt
+func (c *Client) attemptRequest(ctx context.Context, req *http.Request, attempt int) *http.Request {
+	attemptReq := req.Clone(ctx)
+	if attempt > 0 && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			attemptReq.Body = body
+		}
+	}
+	return attemptReq
+}
+
+// shouldRetry reports whether an attempt failed in a way worth retrying:
+// a network error or a 5xx status, while ctx is still live
+func (c *Client) shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode >= http.StatusInternalServerError
+}
+
+// expiresWithin reports whether ctx's deadline passes within d, so a retry
+// after waiting d would be cut short
+func (c *Client) expiresWithin(ctx context.Context, d time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return ok && deadline.Sub(c.clock.Now()) < d
+}
+
+// retryDelay returns the backoff before retry number attempt+1: the base
+// backoff doubled per attempt up to maxRetryBackoff, with the upper half
+// randomized so clients retrying together spread out
+func (c *Client) retryDelay(attempt int) time.Duration {
+	backoff := c.retryBackoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+	backoff = min(backoff, maxRetryBackoff)
+
+	// Clamp before shifting so large attempts cannot overflow
+	delay := maxRetryBackoff
+	if attempt < 63 && backoff <= maxRetryBackoff>>attempt {
+		delay = backoff << attempt
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
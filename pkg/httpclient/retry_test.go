@@ -0,0 +1,261 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tracer-test/pkg/clock"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestClient_Get_Retries(t *testing.T) {
+	// Create a flaky test server that fails twice, then succeeds
+	var hits atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Create a test logger with observer
+	core, recorded := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{
+		Timeout:      5 * time.Second,
+		MaxRetries:   3,
+		RetryBackoff: time.Millisecond,
+	}, logger, tracer)
+	defer client.Close()
+
+	resp, err := client.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Get() status = %d, expected %d", resp.StatusCode, http.StatusOK)
+	}
+	if hits.Load() != 3 {
+		t.Errorf("Server saw %d requests, expected 3", hits.Load())
+	}
+	if n := recorded.FilterMessage("Retrying HTTP request").Len(); n != 2 {
+		t.Errorf("Logged %d retries, expected 2", n)
+	}
+
+	// Check one attempt span per request and the final status
+	var attempts []int64
+	var attemptStatuses []codes.Code
+	for _, s := range recorder.Ended() {
+		switch s.Name() {
+		case "http.get.attempt":
+			for _, attr := range s.Attributes() {
+				if attr.Key == "http.retry.count" {
+					attempts = append(attempts, attr.Value.AsInt64())
+				}
+			}
+			attemptStatuses = append(attemptStatuses, s.Status().Code)
+		case "http.get":
+			if s.Status().Code != codes.Ok {
+				t.Errorf("http.get status = %v, expected Ok after the last attempt succeeded", s.Status().Code)
+			}
+			for _, attr := range s.Attributes() {
+				if attr.Key == "http.retry.count" && attr.Value.AsInt64() != 2 {
+					t.Errorf("http.get http.retry.count = %d, expected 2", attr.Value.AsInt64())
+				}
+			}
+		}
+	}
+	if len(attempts) != 3 || attempts[0] != 0 || attempts[1] != 1 || attempts[2] != 2 {
+		t.Errorf("http.get.attempt retry counts = %v, expected [0 1 2]", attempts)
+	}
+	expected := []codes.Code{codes.Error, codes.Error, codes.Ok}
+	for i, code := range attemptStatuses {
+		if i < len(expected) && code != expected[i] {
+			t.Errorf("Attempt %d status = %v, expected %v", i, code, expected[i])
+		}
+	}
+}
+
+func TestClient_Get_RetriesExhausted(t *testing.T) {
+	// Create a test server that always fails
+	var hits atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// Create a test logger with observer
+	core, recorded := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{
+		Timeout:      5 * time.Second,
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+	}, logger, tracer)
+	defer client.Close()
+
+	resp, err := client.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	// The last attempt's response is returned
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Get() status = %d, expected %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+	if hits.Load() != 3 {
+		t.Errorf("Server saw %d requests, expected 3", hits.Load())
+	}
+
+	// Check that the exhaustion was counted and logged with its context
+	if got := client.RetriesExhausted(); got != 1 {
+		t.Errorf("RetriesExhausted() = %d, expected 1", got)
+	}
+	logs := recorded.FilterMessage("retries_exhausted").All()
+	if len(logs) != 1 {
+		t.Fatalf("Expected 1 retries_exhausted log entry, got %d", len(logs))
+	}
+	var traceID string
+	for _, s := range recorder.Ended() {
+		if s.Name() == "http.get" {
+			traceID = s.SpanContext().TraceID().String()
+		}
+	}
+	fields := logs[0].ContextMap()
+	if logs[0].Level != zapcore.ErrorLevel {
+		t.Errorf("retries_exhausted level = %v, expected error", logs[0].Level)
+	}
+	if fields["attempts"] != int64(3) {
+		t.Errorf("attempts = %v, expected 3", fields["attempts"])
+	}
+	if fields["last_error"] != "HTTP 503" {
+		t.Errorf("last_error = %v, expected HTTP 503", fields["last_error"])
+	}
+	if fields["url"] != server.URL {
+		t.Errorf("url = %v, expected %s", fields["url"], server.URL)
+	}
+	if fields["trace_id"] != traceID {
+		t.Errorf("trace_id = %v, expected %s", fields["trace_id"], traceID)
+	}
+}
+
+func TestClient_Get_RetryCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create a test server that fails and cancels the caller
+	var hits atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		cancel()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	client := New(Config{
+		Timeout:      5 * time.Second,
+		MaxRetries:   5,
+		RetryBackoff: time.Hour,
+	}, logger, noop.NewTracerProvider().Tracer("test"))
+	defer client.Close()
+
+	start := time.Now()
+	resp, err := client.Get(ctx, server.URL)
+	if err == nil {
+		resp.Body.Close()
+	}
+
+	if hits.Load() != 1 {
+		t.Errorf("Server saw %d requests, expected 1", hits.Load())
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Get() took %s, expected to stop immediately on cancellation", elapsed)
+	}
+}
+
+func TestClient_retryDelay(t *testing.T) {
+	client := &Client{retryBackoff: time.Second}
+
+	for _, attempt := range []int{0, 1, 5, 30, 63, 64, 100} {
+		delay := client.retryDelay(attempt)
+		if delay <= 0 || delay > maxRetryBackoff {
+			t.Errorf("retryDelay(%d) = %s, expected between 0 and %s", attempt, delay, maxRetryBackoff)
+		}
+	}
+	if delay := client.retryDelay(100); delay < maxRetryBackoff/2 {
+		t.Errorf("retryDelay(100) = %s, expected at least %s", delay, maxRetryBackoff/2)
+	}
+}
+
+func TestClient_deadlineUsesClock(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 30, 45, 0, time.UTC)
+	client := &Client{
+		timeout: 5 * time.Second,
+		clock:   clock.NewFake(now),
+		logger:  zap.NewNop(),
+	}
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(2*time.Second))
+	defer cancel()
+
+	if got := client.effectiveTimeout(ctx, "http://example.com"); got != 2*time.Second {
+		t.Errorf("effectiveTimeout() = %s, expected %s", got, 2*time.Second)
+	}
+	if client.expiresWithin(ctx, time.Second) {
+		t.Error("expiresWithin(1s) = true, expected false")
+	}
+	if !client.expiresWithin(ctx, 3*time.Second) {
+		t.Error("expiresWithin(3s) = false, expected true")
+	}
+}
+
+func TestConfig_Validate_MaxRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRetries int
+		wantErr    bool
+	}{
+		{"disabled", 0, false},
+		{"limit", maxRetriesLimit, false},
+		{"negative", -1, true},
+		{"above limit", maxRetriesLimit + 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := (Config{MaxRetries: tt.maxRetries}).Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package httpclient
+
+import (
+	"errors"
+	"net/url"
+)
+
+// redactedValue replaces scrubbed query parameter values
+const redactedValue = "REDACTED"
+
+// urlScrubber redacts query parameters from URLs recorded in spans and logs.
+// A nil scrubber leaves URLs unchanged.
+type urlScrubber struct {
+	params map[string]bool
+	all    bool
+}
+
+// newURLScrubber returns a scrubber for the named parameters, or for the
+// whole query when all is set. It returns nil when there is nothing to scrub.
+func newURLScrubber(params []string, all bool) *urlScrubber {
+	if len(params) == 0 && !all {
+		return nil
+	}
+	s := &urlScrubber{params: make(map[string]bool, len(params)), all: all}
+	for _, p := range params {
+		s.params[p] = true
+	}
+	return s
+}
+
+// scrub returns raw with the configured query parameters redacted
+func (s *urlScrubber) scrub(raw string) string {
+	if s == nil {
+		return raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.RawQuery == "" {
+		return raw
+	}
+	return s.scrubURL(u)
+}
+
+// scrubURL returns u as a string with the configured query parameters redacted
+func (s *urlScrubber) scrubURL(u *url.URL) string {
+	if s == nil || u.RawQuery == "" {
+		return u.String()
+	}
+
+	scrubbed := *u
+	if s.all {
+		scrubbed.RawQuery = ""
+		return scrubbed.String()
+	}
+
+	query := scrubbed.Query()
+	for name := range query {
+		if s.params[name] {
+			query[name] = []string{redactedValue}
+		}
+	}
+	scrubbed.RawQuery = query.Encode()
+	return scrubbed.String()
+}
+
+// scrubError redacts the URL carried by a *url.Error from the HTTP client
+func (s *urlScrubber) scrubError(err error) error {
+	var urlErr *url.Error
+	if s == nil || !errors.As(err, &urlErr) {
+		return err
+	}
+	urlErr.URL = s.scrub(urlErr.URL)
+	return err
+}
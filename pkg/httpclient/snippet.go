@@ -0,0 +1,25 @@
+package httpclient
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// snippetBody replays a peeked prefix before the rest of the original body
+type snippetBody struct {
+	io.Reader
+	io.Closer
+}
+
+// peekBody reads up to n bytes from the start of resp.Body and returns them
+// as valid UTF-8, leaving the full body readable by the caller
+func peekBody(resp *http.Response, n int) string {
+	prefix, _ := io.ReadAll(io.LimitReader(resp.Body, int64(n)))
+	resp.Body = snippetBody{
+		Reader: io.MultiReader(bytes.NewReader(prefix), resp.Body),
+		Closer: resp.Body,
+	}
+	return strings.ToValidUTF8(string(prefix), "")
+}
@@ -0,0 +1,89 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"time"
+)
+
+// dialFunc dials a connection for the transport
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// socketTimeoutError is returned when a single read or write on a
+// connection stalls past its socket deadline
+type socketTimeoutError struct {
+	op      string
+	timeout time.Duration
+	err     error
+}
+
+// Error implements error
+func (e *socketTimeoutError) Error() string {
+	return "socket " + e.op + " timed out after " + e.timeout.String() + ": " + e.err.Error()
+}
+
+func (e *socketTimeoutError) Unwrap() error { return e.err }
+
+// Timeout implements net.Error
+func (e *socketTimeoutError) Timeout() bool { return true }
+
+// Temporary implements net.Error
+func (e *socketTimeoutError) Temporary() bool { return true }
+
+// isSocketTimeout reports whether err comes from a stalled socket read or
+// write
+func isSocketTimeout(err error) bool {
+	var timeoutErr *socketTimeoutError
+	return errors.As(err, &timeoutErr)
+}
+
+// deadlineConn is a connection that bounds each Read and Write with a
+// fresh deadline, so a peer that stops sending or receiving mid-transfer
+// is detected even while the overall request timeout has time left
+type deadlineConn struct {
+	net.Conn
+	read  time.Duration
+	write time.Duration
+}
+
+// Read implements net.Conn
+func (c *deadlineConn) Read(p []byte) (int, error) {
+	if c.read > 0 {
+		if err := c.Conn.SetReadDeadline(time.Now().Add(c.read)); err != nil {
+			return 0, err
+		}
+	}
+	n, err := c.Conn.Read(p)
+	if err != nil && c.read > 0 && errors.Is(err, os.ErrDeadlineExceeded) {
+		err = &socketTimeoutError{op: "read", timeout: c.read, err: err}
+	}
+	return n, err
+}
+
+// Write implements net.Conn
+func (c *deadlineConn) Write(p []byte) (int, error) {
+	if c.write > 0 {
+		if err := c.Conn.SetWriteDeadline(time.Now().Add(c.write)); err != nil {
+			return 0, err
+		}
+	}
+	n, err := c.Conn.Write(p)
+	if err != nil && c.write > 0 && errors.Is(err, os.ErrDeadlineExceeded) {
+		err = &socketTimeoutError{op: "write", timeout: c.write, err: err}
+	}
+	return n, err
+}
+
+// socketDeadlines wraps dial so its connections enforce the read and write
+// deadlines. A zero timeout leaves that direction unbounded.
+func socketDeadlines(dial dialFunc, read, write time.Duration) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return &deadlineConn{Conn: conn, read: read, write: write}, nil
+	}
+}
@@ -0,0 +1,74 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+)
+
+func TestClient_ReadBody_SocketReadTimeout(t *testing.T) {
+	// Create a test server that stalls after sending part of the body
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "1024")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := New(Config{
+		Timeout:           10 * time.Second,
+		SocketReadTimeout: 100 * time.Millisecond,
+	}, zap.NewNop(), tracer)
+	defer client.Close()
+
+	ctx, span := tracer.Start(context.Background(), "request.cycle")
+	resp, err := client.Get(ctx, server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	start := time.Now()
+	_, err = client.ReadBody(ctx, resp)
+	elapsed := time.Since(start)
+	span.End()
+
+	if err == nil {
+		t.Fatal("ReadBody() error = nil, expected a socket read timeout")
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("ReadBody() took %s, expected to fail promptly after the read deadline", elapsed)
+	}
+	if category := ErrorCategory(err); category != failureSocketTimeout {
+		t.Errorf("ErrorCategory() = %q, expected %q", category, failureSocketTimeout)
+	}
+
+	// Check that the failure was recorded on the span
+	var category string
+	for _, s := range recorder.Ended() {
+		if s.Name() != "request.cycle" {
+			continue
+		}
+		for _, attr := range s.Attributes() {
+			if attr.Key == "error.category" {
+				category = attr.Value.AsString()
+			}
+		}
+	}
+	if category != failureSocketTimeout {
+		t.Errorf("request.cycle error.category = %q, expected %q", category, failureSocketTimeout)
+	}
+}
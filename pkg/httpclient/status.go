@@ -0,0 +1,40 @@
+package httpclient
+
+// statusPolicy decides which HTTP status codes are treated as errors
+type statusPolicy struct {
+	nonError map[int]bool
+	expected map[int]bool
+}
+
+// newStatusPolicy creates a policy treating codes >= 400 as errors, except
+// those listed in nonErrorCodes. When expectedCodes is not empty, every code
+// outside it is an error instead, regardless of the 400 boundary.
+func newStatusPolicy(nonErrorCodes, expectedCodes []int) *statusPolicy {
+	policy := &statusPolicy{nonError: make(map[int]bool, len(nonErrorCodes))}
+	for _, code := range nonErrorCodes {
+		policy.nonError[code] = true
+	}
+	if len(expectedCodes) > 0 {
+		policy.expected = make(map[int]bool, len(expectedCodes))
+		for _, code := range expectedCodes {
+			policy.expected[code] = true
+		}
+	}
+	return policy
+}
+
+// isError reports whether code should mark spans and logs as errors
+func (p *statusPolicy) isError(code int) bool {
+	if p == nil {
+		return code >= 400
+	}
+	if p.expected != nil {
+		return !p.expected[code]
+	}
+	return code >= 400 && !p.nonError[code]
+}
+
+// isUnexpected reports whether code falls outside the expected status codes
+func (p *statusPolicy) isUnexpected(code int) bool {
+	return p != nil && p.expected != nil && !p.expected[code]
+}
@@ -0,0 +1,90 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// streamChunkSize is the buffer size used when streaming response bodies
+const streamChunkSize = 32 * 1024
+
+// defaultStreamProgressBytes is how often GetStream records progress
+const defaultStreamProgressBytes = 1024 * 1024
+
+// GetStream makes a GET request and reads the body in chunks, calling
+// chunkCallback with the size of each chunk and recording a "progress" span
+// event every StreamProgressBytes. The http.stream span ends after the full
+// read with the total bytes. The returned response body has already been closed.
+func (c *Client) GetStream(ctx context.Context, url string, chunkCallback func(n int)) (*http.Response, int64, error) {
+	ctx, span := c.tracer.Start(ctx, "http.stream",
+		trace.WithAttributes(
+			attribute.String("http.url", c.ScrubURL(url)),
+		))
+	defer span.End()
+
+	resp, err := c.Get(ctx, url)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	every := c.streamProgressBytes
+	if every <= 0 {
+		every = defaultStreamProgressBytes
+	}
+
+	var (
+		total        int64
+		nextProgress = every
+		buf          = make([]byte, streamChunkSize)
+	)
+	for {
+		n, readErr := resp.Body.Read(buf)
+		if n > 0 {
+			total += int64(n)
+			c.bytesRead.Add(int64(n))
+			if chunkCallback != nil {
+				chunkCallback(n)
+			}
+			for total >= nextProgress {
+				span.AddEvent("progress", trace.WithAttributes(
+					attribute.Int64("http.response.bytes_read", total),
+				))
+				nextProgress += every
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			err := fmt.Errorf("failed to stream response body: %w", readErr)
+			span.SetAttributes(attribute.Int64("http.response.body_size", total))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			c.logger.Error("Response stream failed",
+				zap.String("url", c.ScrubURL(url)),
+				zap.Int64("bytes_read", total),
+				zap.Error(err))
+			return resp, total, err
+		}
+	}
+
+	span.SetAttributes(attribute.Int64("http.response.body_size", total))
+	c.recordTrailers(span, resp.Trailer)
+	if c.IsErrorStatus(resp.StatusCode) {
+		span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", resp.StatusCode))
+	} else {
+		span.SetStatus(codes.Ok, "")
+	}
+	return resp, total, nil
+}
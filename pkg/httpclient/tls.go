@@ -0,0 +1,76 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tlsVersions maps MinTLSVersion values to crypto/tls constants
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// defaultTLSSessionCacheSize is the number of TLS sessions cached for
+// resumption when TLSSessionCacheSize is zero
+const defaultTLSSessionCacheSize = 32
+
+// newTLSConfig builds the transport TLS configuration from config, returning
+// nil when no TLS options are set and the session cache is disabled
+func newTLSConfig(config Config) (*tls.Config, error) {
+	cacheSize := config.TLSSessionCacheSize
+	if cacheSize == 0 {
+		cacheSize = defaultTLSSessionCacheSize
+	}
+	if config.MinTLSVersion == "" && len(config.CipherSuites) == 0 && cacheSize < 0 {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{}
+	if cacheSize > 0 {
+		tlsConfig.ClientSessionCache = tls.NewLRUClientSessionCache(cacheSize)
+	}
+	if config.MinTLSVersion != "" {
+		version, ok := tlsVersions[config.MinTLSVersion]
+		if !ok {
+			return nil, fmt.Errorf("unknown TLS version %q (expected 1.0, 1.1, 1.2 or 1.3)", config.MinTLSVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+
+	if len(config.CipherSuites) > 0 {
+		known := make(map[string]uint16)
+		for _, suite := range tls.CipherSuites() {
+			known[suite.Name] = suite.ID
+		}
+		for _, name := range config.CipherSuites {
+			id, ok := known[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown or insecure cipher suite %q", name)
+			}
+			tlsConfig.CipherSuites = append(tlsConfig.CipherSuites, id)
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// recordTLSState records the negotiated TLS version and cipher suite on span,
+// and whether the handshake resumed a cached session
+func recordTLSState(span trace.Span, state *tls.ConnectionState) {
+	if state == nil {
+		return
+	}
+	span.SetAttributes(
+		attribute.String("tls.protocol.name", "tls"),
+		attribute.String("tls.protocol.version", strings.TrimPrefix(tls.VersionName(state.Version), "TLS ")),
+		attribute.String("tls.cipher", tls.CipherSuiteName(state.CipherSuite)),
+		attribute.Bool("tls.resumed", state.DidResume),
+	)
+}
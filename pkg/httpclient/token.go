@@ -0,0 +1,58 @@
+package httpclient
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"tracer-test/pkg/clock"
+)
+
+// defaultBearerTokenTTL is how long a token read from file is reused
+const defaultBearerTokenTTL = 10 * time.Second
+
+// tokenFile reads a bearer token from a file that may be rewritten at any
+// time, caching it for ttl
+type tokenFile struct {
+	path  string
+	ttl   time.Duration
+	clock clock.Clock
+
+	mu     sync.Mutex
+	token  string
+	readAt time.Time
+}
+
+// newTokenFile creates a token source for path
+func newTokenFile(path string, ttl time.Duration, clk clock.Clock) *tokenFile {
+	if ttl <= 0 {
+		ttl = defaultBearerTokenTTL
+	}
+	return &tokenFile{path: path, ttl: ttl, clock: clk}
+}
+
+// get returns the current token, rereading the file once the cache expires
+func (f *tokenFile) get() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.clock.Now()
+	if f.token != "" && now.Sub(f.readAt) < f.ttl {
+		return f.token, nil
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read bearer token file: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("bearer token file %s is empty", f.path)
+	}
+
+	f.token = token
+	f.readAt = now
+	return token, nil
+}
@@ -0,0 +1,22 @@
+package httpclient
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordTrailers records the configured response trailers on span as
+// http.response.trailer.<name> attributes. Trailers are only populated once
+// the body has been read to EOF.
+func (c *Client) recordTrailers(span trace.Span, trailer http.Header) {
+	for _, name := range c.trailers {
+		value := trailer.Get(name)
+		if value == "" {
+			continue
+		}
+		span.SetAttributes(attribute.String("http.response.trailer."+strings.ToLower(name), value))
+	}
+}
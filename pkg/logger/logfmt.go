@@ -0,0 +1,127 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// logfmtPool recycles buffers for encoded logfmt lines
+var logfmtPool = buffer.NewPool()
+
+// logfmtEncoder encodes entries as logfmt key=value lines. The timestamp,
+// level, caller and message come first, followed by fields sorted by key.
+type logfmtEncoder struct {
+	*zapcore.MapObjectEncoder
+	config zapcore.EncoderConfig
+}
+
+// newLogfmtEncoder creates a logfmt encoder using the keys in config
+func newLogfmtEncoder(config zapcore.EncoderConfig) zapcore.Encoder {
+	return &logfmtEncoder{
+		MapObjectEncoder: zapcore.NewMapObjectEncoder(),
+		config:           config,
+	}
+}
+
+// Clone implements zapcore.Encoder
+func (e *logfmtEncoder) Clone() zapcore.Encoder {
+	clone := &logfmtEncoder{
+		MapObjectEncoder: zapcore.NewMapObjectEncoder(),
+		config:           e.config,
+	}
+	for key, value := range e.Fields {
+		clone.Fields[key] = value
+	}
+	return clone
+}
+
+// EncodeEntry implements zapcore.Encoder
+func (e *logfmtEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	enc := e.Clone().(*logfmtEncoder)
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+
+	line := logfmtPool.Get()
+	if e.config.TimeKey != "" {
+		writeLogfmtPair(line, e.config.TimeKey, ent.Time.Format("2006-01-02T15:04:05.000Z0700"))
+	}
+	if e.config.LevelKey != "" {
+		writeLogfmtPair(line, e.config.LevelKey, ent.Level.String())
+	}
+	if e.config.CallerKey != "" && ent.Caller.Defined {
+		writeLogfmtPair(line, e.config.CallerKey, ent.Caller.TrimmedPath())
+	}
+	if e.config.MessageKey != "" {
+		writeLogfmtPair(line, e.config.MessageKey, ent.Message)
+	}
+
+	keys := make([]string, 0, len(enc.Fields))
+	for key := range enc.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		writeLogfmtPair(line, key, logfmtValue(enc.Fields[key]))
+	}
+
+	if e.config.StacktraceKey != "" && ent.Stack != "" {
+		writeLogfmtPair(line, e.config.StacktraceKey, ent.Stack)
+	}
+	line.AppendString("\n")
+	return line, nil
+}
+
+// writeLogfmtPair appends key=value, quoting the value when needed
+func writeLogfmtPair(line *buffer.Buffer, key, value string) {
+	if line.Len() > 0 {
+		line.AppendByte(' ')
+	}
+	line.AppendString(key)
+	line.AppendByte('=')
+	if needsLogfmtQuotes(value) {
+		line.AppendString(fmt.Sprintf("%q", value))
+	} else {
+		line.AppendString(value)
+	}
+}
+
+// needsLogfmtQuotes reports whether value must be quoted to parse as a
+// single logfmt value
+func needsLogfmtQuotes(value string) bool {
+	if value == "" {
+		return true
+	}
+	return strings.IndexFunc(value, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || r == '\\' || !unicode.IsPrint(r)
+	}) >= 0
+}
+
+// logfmtValue renders a field value captured by the map encoder
+func logfmtValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case time.Duration:
+		return v.String()
+	case fmt.Stringer:
+		return v.String()
+	case map[string]interface{}, []interface{}:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(encoded)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -1,7 +1,12 @@
 package logger
 
 import (
+	"fmt"
+	"io"
 	"os"
+	"sort"
+	"text/template"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -10,12 +15,38 @@ import (
 // Logger wraps the zap logger
 type Logger struct {
 	*zap.Logger
+
+	// buffer holds encoded entries until they are flushed, nil when
+	// output is unbuffered
+	buffer *zapcore.BufferedWriteSyncer
+	// traceURL renders the trace_url field of WithTraceContext, nil when
+	// no template is configured
+	traceURL *template.Template
 }
 
 // Config holds logger configuration
 type Config struct {
 	Level  string
 	Format string
+	// Output receives encoded log entries. Defaults to os.Stdout when nil.
+	Output io.Writer
+	// Fields are added to every log entry, e.g. the deployment environment
+	Fields map[string]string
+	// FieldNames renames the standard keys of JSON and logfmt output
+	// (timestamp, level, msg, caller), e.g. {"level": "severity"}. Keys
+	// left out keep their default names.
+	FieldNames map[string]string
+	// BufferSize, when positive, buffers up to this many bytes of encoded
+	// entries before writing them to Output, flushing at least every
+	// FlushInterval (default 30s) and on Sync. Zero writes every entry
+	// immediately.
+	BufferSize    int
+	FlushInterval time.Duration
+	// TraceURLTemplate, when set, adds a trace_url field rendered from
+	// this text/template to WithTraceContext loggers, e.g.
+	// "https://jaeger.example.com/trace/{{.TraceID}}". {{.SpanID}} is also
+	// available.
+	TraceURLTemplate string
 }
 
 // Custom log writer that converts standard log output to JSON
@@ -36,6 +67,24 @@ func (w *jsonLogWriter) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
+// renameFields applies custom names to the standard keys of config
+func renameFields(config *zapcore.EncoderConfig, names map[string]string) error {
+	keys := map[string]*string{
+		config.TimeKey:    &config.TimeKey,
+		config.LevelKey:   &config.LevelKey,
+		config.MessageKey: &config.MessageKey,
+		config.CallerKey:  &config.CallerKey,
+	}
+	for from, to := range names {
+		key, ok := keys[from]
+		if !ok {
+			return fmt.Errorf("unknown log field %q, expected timestamp, level, msg or caller", from)
+		}
+		*key = to
+	}
+	return nil
+}
+
 // New creates a new logger instance
 func New(config Config) (*Logger, error) {
 	// Parse log level
@@ -62,33 +111,93 @@ func New(config Config) (*Logger, error) {
 		encoderConfig = zap.NewProductionEncoderConfig()
 		encoderConfig.TimeKey = "timestamp"
 		encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+		if err := renameFields(&encoderConfig, config.FieldNames); err != nil {
+			return nil, err
+		}
 	}
 
 	// Create encoder
 	var encoder zapcore.Encoder
-	if config.Format == "console" {
+	switch config.Format {
+	case "console":
 		encoder = zapcore.NewConsoleEncoder(encoderConfig)
-	} else {
+	case "logfmt":
+		encoder = newLogfmtEncoder(encoderConfig)
+	default:
 		encoder = zapcore.NewJSONEncoder(encoderConfig)
 	}
 
+	// Resolve output sink
+	output := config.Output
+	if output == nil {
+		output = os.Stdout
+	}
+
+	// Buffer writes when requested
+	sink := zapcore.AddSync(output)
+	var buffer *zapcore.BufferedWriteSyncer
+	if config.BufferSize > 0 {
+		buffer = &zapcore.BufferedWriteSyncer{
+			WS:            sink,
+			Size:          config.BufferSize,
+			FlushInterval: config.FlushInterval,
+		}
+		sink = buffer
+	}
+
+	// Parse the trace URL template
+	var traceURL *template.Template
+	if config.TraceURLTemplate != "" {
+		var err error
+		if traceURL, err = parseTraceURL(config.TraceURLTemplate); err != nil {
+			return nil, err
+		}
+	}
+
 	// Create core
-	core := zapcore.NewCore(encoder, zapcore.AddSync(os.Stdout), level)
+	core := zapcore.NewCore(encoder, sink, level)
 
 	// Create logger
 	logger := zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
+	if len(config.Fields) > 0 {
+		keys := make([]string, 0, len(config.Fields))
+		for key := range config.Fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		fields := make([]zap.Field, 0, len(keys))
+		for _, key := range keys {
+			fields = append(fields, zap.String(key, config.Fields[key]))
+		}
+		logger = logger.With(fields...)
+	}
 
 	// Note: OTLP export errors will be handled by the exporter itself
 	// We can't easily redirect them to our structured logger
 
-	return &Logger{Logger: logger}, nil
+	return &Logger{Logger: logger, buffer: buffer, traceURL: traceURL}, nil
+}
+
+// Close flushes buffered entries and stops the periodic flush. It is a no-op
+// for unbuffered loggers.
+func (l *Logger) Close() error {
+	if l.buffer == nil {
+		return nil
+	}
+	return l.buffer.Stop()
 }
 
 
-// WithTraceContext adds trace and span context to the logger
+// WithTraceContext adds trace and span context to the logger, with a link
+// to the trace when a trace URL template is configured
 func (l *Logger) WithTraceContext(traceID, spanID string) *zap.Logger {
-	return l.Logger.With(
+	fields := []zap.Field{
 		zap.String("trace_id", traceID),
 		zap.String("span_id", spanID),
-	)
+	}
+	if url, ok := l.renderTraceURL(traceID, spanID); ok {
+		fields = append(fields, zap.String("trace_url", url))
+	}
+	return l.Logger.With(fields...)
 }
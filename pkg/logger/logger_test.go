@@ -1,7 +1,12 @@
 package logger
 
 import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -81,6 +86,43 @@ func TestLogger_WithTraceContext(t *testing.T) {
 	}
 }
 
+func TestLogger_WithTraceContext_TraceURL(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, err := New(Config{
+		Level:            "info",
+		Format:           "json",
+		Output:           &buf,
+		TraceURLTemplate: "https://jaeger.example.com/trace/{{.TraceID}}",
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	logger.WithTraceContext(traceID, "00f067aa0ba902b7").Info("test message")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Output %q is not valid JSON: %v", buf.String(), err)
+	}
+	if expected := "https://jaeger.example.com/trace/" + traceID; entry["trace_url"] != expected {
+		t.Errorf("Output field trace_url = %v, expected %s", entry["trace_url"], expected)
+	}
+
+	// Without a trace there is nothing to link to
+	buf.Reset()
+	logger.WithTraceContext("00000000000000000000000000000000", "0000000000000000").Info("test message")
+	if strings.Contains(buf.String(), "trace_url") {
+		t.Errorf("Output %q has a trace_url for an unset trace ID", buf.String())
+	}
+
+	// Unknown fields are rejected up front
+	if _, err := New(Config{TraceURLTemplate: "https://jaeger.example.com/trace/{{.Trace}}"}); err == nil {
+		t.Error("New() error = nil, expected an error for an unknown template field")
+	}
+}
+
 func TestJsonLogWriter(t *testing.T) {
 	// Create a test logger with observer
 	core, recorded := observer.New(zapcore.InfoLevel)
@@ -168,7 +210,7 @@ func TestLogLevels(t *testing.T) {
 }
 
 func TestLogFormats(t *testing.T) {
-	formats := []string{"json", "console"}
+	formats := []string{"json", "console", "logfmt"}
 	
 	for _, format := range formats {
 		t.Run(format, func(t *testing.T) {
@@ -189,3 +231,207 @@ func TestLogFormats(t *testing.T) {
 		})
 	}
 }
+
+func TestNew_Output(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, err := New(Config{
+		Level:  "info",
+		Format: "json",
+		Output: &buf,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	logger.WithTraceContext("1234567890abcdef", "abcdef1234567890").Info("test message")
+
+	// Check that the output is valid JSON with the expected fields
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Output %q is not valid JSON: %v", buf.String(), err)
+	}
+
+	expected := map[string]string{
+		"level":    "info",
+		"msg":      "test message",
+		"trace_id": "1234567890abcdef",
+		"span_id":  "abcdef1234567890",
+	}
+	for key, value := range expected {
+		if entry[key] != value {
+			t.Errorf("Output field %s = %v, expected %s", key, entry[key], value)
+		}
+	}
+	if _, ok := entry["timestamp"]; !ok {
+		t.Error("Output expected to contain timestamp field")
+	}
+}
+
+func TestNew_Logfmt(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, err := New(Config{
+		Level:  "info",
+		Format: "logfmt",
+		Output: &buf,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	logger.WithTraceContext("1234567890abcdef", "abcdef1234567890").Info("test message",
+		zap.String("url", "http://example.com/a b"),
+		zap.Int("status_code", 200),
+		zap.String("quote", `say "hi"`))
+
+	line := strings.TrimSuffix(buf.String(), "\n")
+	if strings.Contains(line, "\n") {
+		t.Fatalf("Output %q expected to be a single line", buf.String())
+	}
+
+	// Parse the line as logfmt key=value pairs
+	entry := map[string]string{}
+	for rest := line; rest != ""; {
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			t.Fatalf("Output %q is not valid logfmt near %q", line, rest)
+		}
+		key := rest[:eq]
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				t.Fatalf("Output %q has a bad quoted value for %s: %v", line, key, err)
+			}
+			value, _ = strconv.Unquote(quoted)
+			rest = rest[len(quoted):]
+		} else {
+			end := strings.IndexByte(rest, ' ')
+			if end < 0 {
+				end = len(rest)
+			}
+			value = rest[:end]
+			rest = rest[end:]
+		}
+		entry[key] = value
+		rest = strings.TrimPrefix(rest, " ")
+	}
+
+	expected := map[string]string{
+		"level":       "info",
+		"msg":         "test message",
+		"trace_id":    "1234567890abcdef",
+		"span_id":     "abcdef1234567890",
+		"url":         "http://example.com/a b",
+		"status_code": "200",
+		"quote":       `say "hi"`,
+	}
+	for key, value := range expected {
+		if entry[key] != value {
+			t.Errorf("Output field %s = %q, expected %q", key, entry[key], value)
+		}
+	}
+	if _, ok := entry["timestamp"]; !ok {
+		t.Error("Output expected to contain timestamp field")
+	}
+	if !strings.Contains(line, `msg="test message"`) {
+		t.Errorf("Output %q expected to quote values with spaces", line)
+	}
+}
+
+func TestNew_Fields(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, err := New(Config{
+		Level:  "info",
+		Format: "json",
+		Output: &buf,
+		Fields: map[string]string{"deployment.environment": "staging"},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	logger.Info("test message")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Output %q is not valid JSON: %v", buf.String(), err)
+	}
+	if entry["deployment.environment"] != "staging" {
+		t.Errorf("Output field deployment.environment = %v, expected staging", entry["deployment.environment"])
+	}
+}
+
+func TestNew_FieldNames(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, err := New(Config{
+		Level:      "info",
+		Format:     "json",
+		Output:     &buf,
+		FieldNames: map[string]string{"level": "severity", "msg": "message", "timestamp": "time"},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	logger.Info("test message")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Output %q is not valid JSON: %v", buf.String(), err)
+	}
+	for key, expected := range map[string]interface{}{"severity": "info", "message": "test message"} {
+		if entry[key] != expected {
+			t.Errorf("Output field %s = %v, expected %v", key, entry[key], expected)
+		}
+	}
+	if _, ok := entry["time"]; !ok {
+		t.Errorf("Output %q expected a time field", buf.String())
+	}
+	for _, key := range []string{"level", "msg", "timestamp"} {
+		if _, ok := entry[key]; ok {
+			t.Errorf("Output %q expected no %s field", buf.String(), key)
+		}
+	}
+	if _, ok := entry["caller"]; !ok {
+		t.Errorf("Output %q expected the caller field to keep its default name", buf.String())
+	}
+
+	// Test that unknown keys are rejected
+	if _, err := New(Config{Format: "json", FieldNames: map[string]string{"lvl": "severity"}}); err == nil {
+		t.Error("New() error = nil, expected an error for an unknown field")
+	}
+}
+
+func TestNew_Buffered(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(Config{
+		Level:         "info",
+		Format:        "json",
+		Output:        &buf,
+		BufferSize:    4096,
+		FlushInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer func() { _ = logger.Close() }()
+
+	logger.Info("buffered message")
+	if buf.Len() != 0 {
+		t.Errorf("Output before flush = %q, expected nothing", buf.String())
+	}
+
+	// Sync flushes the buffered entry
+	if err := logger.Sync(); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `"msg":"buffered message"`) {
+		t.Errorf("Output after flush = %q, expected the buffered message", buf.String())
+	}
+}
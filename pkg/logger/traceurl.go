@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// traceURLData is the data a trace URL template is rendered with
+type traceURLData struct {
+	TraceID string
+	SpanID  string
+}
+
+// parseTraceURL parses a trace URL template such as
+// "https://jaeger.example.com/trace/{{.TraceID}}", rendering it once so
+// references to unknown fields fail at startup rather than per entry
+func parseTraceURL(text string) (*template.Template, error) {
+	tmpl, err := template.New("trace-url").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trace URL template: %w", err)
+	}
+	if err := tmpl.Execute(&strings.Builder{}, traceURLData{}); err != nil {
+		return nil, fmt.Errorf("invalid trace URL template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// renderTraceURL renders the trace URL for traceID, returning false when no
+// template is configured or the trace ID is unset (tracing disabled)
+func (l *Logger) renderTraceURL(traceID, spanID string) (string, bool) {
+	if l.traceURL == nil || strings.Trim(traceID, "0") == "" {
+		return "", false
+	}
+	var b strings.Builder
+	if err := l.traceURL.Execute(&b, traceURLData{TraceID: traceID, SpanID: spanID}); err != nil {
+		return "", false
+	}
+	return b.String(), true
+}
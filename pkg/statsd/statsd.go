@@ -0,0 +1,71 @@
+package statsd
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// Config holds StatsD emitter configuration
+type Config struct {
+	// Addr is the host:port of the StatsD server
+	Addr string
+	// Prefix is prepended to every metric name. Defaults to "tracer_test".
+	Prefix string
+}
+
+// Client sends metrics to a StatsD server over UDP
+type Client struct {
+	conn   net.Conn
+	prefix string
+}
+
+// New creates a new StatsD client
+func New(config Config) (*Client, error) {
+	conn, err := net.Dial("udp", config.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to StatsD: %w", err)
+	}
+
+	prefix := config.Prefix
+	if prefix == "" {
+		prefix = "tracer_test"
+	}
+
+	return &Client{
+		conn:   conn,
+		prefix: prefix,
+	}, nil
+}
+
+// RecordRequest sends the request count, error count and duration timing
+// for a single request as one packet
+func (c *Client) RecordRequest(duration time.Duration, failed bool) error {
+	lines := []string{
+		c.line("http.requests", "1", "c"),
+		c.line("http.request.duration", fmt.Sprintf("%d", duration.Milliseconds()), "ms"),
+	}
+	if failed {
+		lines = append(lines, c.line("http.errors", "1", "c"))
+	}
+	return c.send(lines)
+}
+
+// Close closes the underlying connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// line formats a single StatsD metric line
+func (c *Client) line(name, value, metricType string) string {
+	return fmt.Sprintf("%s.%s:%s|%s", c.prefix, name, value, metricType)
+}
+
+// send writes lines as a single newline-separated packet
+func (c *Client) send(lines []string) error {
+	if _, err := c.conn.Write([]byte(strings.Join(lines, "\n"))); err != nil {
+		return fmt.Errorf("failed to send StatsD packet: %w", err)
+	}
+	return nil
+}
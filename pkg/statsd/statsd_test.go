@@ -0,0 +1,64 @@
+package statsd
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_RecordRequest(t *testing.T) {
+	// Create a local UDP listener
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket() error = %v", err)
+	}
+	defer listener.Close()
+
+	client, err := New(Config{Addr: listener.LocalAddr().String(), Prefix: "test"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer client.Close()
+
+	if err := client.RecordRequest(150*time.Millisecond, true); err != nil {
+		t.Fatalf("RecordRequest() error = %v", err)
+	}
+
+	// Read the packet
+	buf := make([]byte, 1024)
+	_ = listener.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := listener.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("ReadFrom() error = %v", err)
+	}
+	packet := string(buf[:n])
+
+	expected := []string{
+		"test.http.requests:1|c",
+		"test.http.request.duration:150|ms",
+		"test.http.errors:1|c",
+	}
+	lines := strings.Split(packet, "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("Packet = %q, expected %d lines", packet, len(expected))
+	}
+	for i, line := range expected {
+		if lines[i] != line {
+			t.Errorf("Packet line %d = %q, expected %q", i, lines[i], line)
+		}
+	}
+}
+
+func TestClient_SendErrorDoesNotPanic(t *testing.T) {
+	client, err := New(Config{Addr: "127.0.0.1:1"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// Sending to a closed connection returns an error instead of panicking
+	client.Close()
+	if err := client.RecordRequest(time.Millisecond, false); err == nil {
+		t.Error("RecordRequest() expected error on closed connection")
+	}
+}
@@ -0,0 +1,139 @@
+package tracer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// keepFunc decides whether the spans of a finished local trace are exported.
+// root is the local root span; spans includes root and all its descendants.
+type keepFunc func(root sdktrace.ReadOnlySpan, spans []sdktrace.ReadOnlySpan) bool
+
+// bufferedCycle holds the ended spans under a local root span
+type bufferedCycle struct {
+	spans   []sdktrace.ReadOnlySpan
+	members []trace.SpanID
+}
+
+// bufferingProcessor holds ended spans per local root span until that root
+// ends, then forwards them to next only if keep approves. Spans are held in
+// memory for the lifetime of each cycle, trading memory for export volume.
+//
+// Like spanLimiter, cycles are keyed by root span ID rather than trace ID,
+// so /trigger cycles continuing the same caller trace are kept or dropped
+// on their own.
+type bufferingProcessor struct {
+	next sdktrace.SpanProcessor
+	keep keepFunc
+
+	mu sync.Mutex
+	// cycles maps every tracked span, root or descendant, to its cycle
+	cycles map[trace.SpanID]*bufferedCycle
+}
+
+// newBufferingProcessor creates a buffering processor forwarding to next
+func newBufferingProcessor(next sdktrace.SpanProcessor, keep keepFunc) *bufferingProcessor {
+	return &bufferingProcessor{
+		next:   next,
+		keep:   keep,
+		cycles: make(map[trace.SpanID]*bufferedCycle),
+	}
+}
+
+// OnStart implements sdktrace.SpanProcessor
+func (p *bufferingProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	spanID := s.SpanContext().SpanID()
+
+	p.mu.Lock()
+	if isLocalRoot(s.Parent()) {
+		p.cycles[spanID] = &bufferedCycle{}
+	} else if cycle, ok := p.cycles[s.Parent().SpanID()]; ok {
+		p.cycles[spanID] = cycle
+		cycle.members = append(cycle.members, spanID)
+	}
+	p.mu.Unlock()
+
+	p.next.OnStart(parent, s)
+}
+
+// OnEnd implements sdktrace.SpanProcessor
+func (p *bufferingProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	spanID := s.SpanContext().SpanID()
+
+	p.mu.Lock()
+	cycle, ok := p.cycles[spanID]
+	if !ok {
+		// Not started under a tracked root; nothing to hold it for
+		p.mu.Unlock()
+		p.next.OnEnd(s)
+		return
+	}
+	cycle.spans = append(cycle.spans, s)
+	if !isLocalRoot(s.Parent()) {
+		p.mu.Unlock()
+		return
+	}
+	for _, member := range cycle.members {
+		delete(p.cycles, member)
+	}
+	delete(p.cycles, spanID)
+	p.mu.Unlock()
+
+	if !p.keep(s, cycle.spans) {
+		return
+	}
+	for _, span := range cycle.spans {
+		p.next.OnEnd(span)
+	}
+}
+
+// Shutdown implements sdktrace.SpanProcessor
+func (p *bufferingProcessor) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	p.cycles = make(map[trace.SpanID]*bufferedCycle)
+	p.mu.Unlock()
+	return p.next.Shutdown(ctx)
+}
+
+// ForceFlush implements sdktrace.SpanProcessor
+func (p *bufferingProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// keepErrorOrSlow keeps traces containing an error span, or whose root span
+// lasted at least slowThreshold when it is positive
+func keepErrorOrSlow(slowThreshold time.Duration) keepFunc {
+	return func(root sdktrace.ReadOnlySpan, spans []sdktrace.ReadOnlySpan) bool {
+		for _, span := range spans {
+			if span.Status().Code == codes.Error {
+				return true
+			}
+		}
+		return slowThreshold > 0 && root.EndTime().Sub(root.StartTime()) >= slowThreshold
+	}
+}
+
+// keepSlowerThan keeps traces whose root span lasted longer than minDuration,
+// regardless of errors
+func keepSlowerThan(minDuration time.Duration) keepFunc {
+	return func(root sdktrace.ReadOnlySpan, spans []sdktrace.ReadOnlySpan) bool {
+		return root.EndTime().Sub(root.StartTime()) > minDuration
+	}
+}
+
+// keepAll keeps traces that every one of keeps approves
+func keepAll(keeps ...keepFunc) keepFunc {
+	return func(root sdktrace.ReadOnlySpan, spans []sdktrace.ReadOnlySpan) bool {
+		for _, keep := range keeps {
+			if !keep(root, spans) {
+				return false
+			}
+		}
+		return true
+	}
+}
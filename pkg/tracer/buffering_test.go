@@ -0,0 +1,132 @@
+package tracer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestBufferingProcessor_ErrorOnly(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	processor := newBufferingProcessor(sdktrace.NewSimpleSpanProcessor(exporter), keepErrorOrSlow(0))
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(processor))
+	tracer := tp.Tracer("test")
+
+	// A successful cycle should export nothing
+	ctx, root := tracer.Start(context.Background(), "request.cycle")
+	_, child := tracer.Start(ctx, "http.get")
+	child.End()
+	root.End()
+
+	if n := len(exporter.GetSpans()); n != 0 {
+		t.Errorf("Exported %d spans for successful cycle, expected 0", n)
+	}
+
+	// A failing cycle should export all of its spans
+	ctx, root = tracer.Start(context.Background(), "request.cycle")
+	_, child = tracer.Start(ctx, "http.get")
+	child.RecordError(errors.New("connection refused"))
+	child.SetStatus(codes.Error, "connection refused")
+	child.End()
+	root.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("Exported %d spans for failing cycle, expected 2", len(spans))
+	}
+	if spans[0].Name != "http.get" || spans[1].Name != "request.cycle" {
+		t.Errorf("Exported spans = [%s %s], expected [http.get request.cycle]", spans[0].Name, spans[1].Name)
+	}
+}
+
+func TestBufferingProcessor_SharedTrace(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	processor := newBufferingProcessor(sdktrace.NewSimpleSpanProcessor(exporter), keepErrorOrSlow(0))
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(processor))
+	tracer := tp.Tracer("test")
+
+	// Two overlapping /trigger cycles continue the same caller trace
+	caller := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	parent := trace.ContextWithRemoteSpanContext(context.Background(), caller)
+	failingCtx, failing := tracer.Start(parent, "request.cycle")
+	passingCtx, passing := tracer.Start(parent, "request.cycle")
+
+	_, failed := tracer.Start(failingCtx, "http.get")
+	failed.SetStatus(codes.Error, "connection refused")
+	failed.End()
+	_, passed := tracer.Start(passingCtx, "http.get")
+	passed.End()
+
+	// The passing cycle ends first and must not take the failing one's spans
+	passing.End()
+	if n := len(exporter.GetSpans()); n != 0 {
+		t.Errorf("Exported %d spans for the passing cycle, expected 0", n)
+	}
+	failing.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("Exported %d spans for the failing cycle, expected 2", len(spans))
+	}
+	if spans[0].SpanContext.SpanID() != failed.SpanContext().SpanID() ||
+		spans[1].SpanContext.SpanID() != failing.SpanContext().SpanID() {
+		t.Errorf("Exported spans = [%s %s], expected the failing cycle's http.get and request.cycle",
+			spans[0].SpanContext.SpanID(), spans[1].SpanContext.SpanID())
+	}
+}
+
+func TestBufferingProcessor_SlowCycle(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	processor := newBufferingProcessor(sdktrace.NewSimpleSpanProcessor(exporter), keepErrorOrSlow(20*time.Millisecond))
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(processor))
+	tracer := tp.Tracer("test")
+
+	// A slow cycle should be exported even without errors
+	_, root := tracer.Start(context.Background(), "request.cycle")
+	time.Sleep(30 * time.Millisecond)
+	root.End()
+
+	if n := len(exporter.GetSpans()); n != 1 {
+		t.Errorf("Exported %d spans for slow cycle, expected 1", n)
+	}
+}
+
+func TestBufferingProcessor_MinSpanDuration(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	processor := newBufferingProcessor(sdktrace.NewSimpleSpanProcessor(exporter), keepSlowerThan(100*time.Millisecond))
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(processor))
+	tracer := tp.Tracer("test")
+	start := time.Now()
+
+	// A fast cycle should export nothing, even when it failed
+	ctx, root := tracer.Start(context.Background(), "request.cycle", trace.WithTimestamp(start))
+	_, child := tracer.Start(ctx, "http.get", trace.WithTimestamp(start))
+	child.SetStatus(codes.Error, "HTTP 500")
+	child.End(trace.WithTimestamp(start.Add(10 * time.Millisecond)))
+	root.End(trace.WithTimestamp(start.Add(10 * time.Millisecond)))
+
+	if n := len(exporter.GetSpans()); n != 0 {
+		t.Errorf("Exported %d spans for fast cycle, expected 0", n)
+	}
+
+	// A slow cycle should export all of its spans
+	ctx, root = tracer.Start(context.Background(), "request.cycle", trace.WithTimestamp(start))
+	_, child = tracer.Start(ctx, "http.get", trace.WithTimestamp(start))
+	child.End(trace.WithTimestamp(start.Add(150 * time.Millisecond)))
+	root.End(trace.WithTimestamp(start.Add(150 * time.Millisecond)))
+
+	if n := len(exporter.GetSpans()); n != 2 {
+		t.Errorf("Exported %d spans for slow cycle, expected 2", n)
+	}
+}
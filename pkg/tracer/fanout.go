@@ -0,0 +1,47 @@
+package tracer
+
+import (
+	"context"
+	"errors"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// fanoutProcessor forwards every span to each of its processors, so a
+// processor placed in front of it, such as the buffering processor, runs
+// once for all exporters rather than once per exporter
+type fanoutProcessor []sdktrace.SpanProcessor
+
+// OnStart implements sdktrace.SpanProcessor
+func (f fanoutProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	for _, p := range f {
+		p.OnStart(parent, s)
+	}
+}
+
+// OnEnd implements sdktrace.SpanProcessor
+func (f fanoutProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	for _, p := range f {
+		p.OnEnd(s)
+	}
+}
+
+// Shutdown implements sdktrace.SpanProcessor, shutting down every processor
+// and returning their errors joined
+func (f fanoutProcessor) Shutdown(ctx context.Context) error {
+	var errs []error
+	for _, p := range f {
+		errs = append(errs, p.Shutdown(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// ForceFlush implements sdktrace.SpanProcessor, flushing every processor and
+// returning their errors joined
+func (f fanoutProcessor) ForceFlush(ctx context.Context) error {
+	var errs []error
+	for _, p := range f {
+		errs = append(errs, p.ForceFlush(ctx))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,112 @@
+package tracer
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/zap"
+)
+
+// defaultExportFailureThreshold is the number of consecutive failed exports
+// after which the export pipeline is considered unhealthy
+const defaultExportFailureThreshold = 3
+
+// exportMonitor wraps an exporter and tracks consecutive export failures
+type exportMonitor struct {
+	sdktrace.SpanExporter
+
+	failures atomic.Int64
+	mu       sync.Mutex
+	lastErr  error
+
+	// queue counts spans dropped ahead of this exporter, nil when the batch
+	// processor blocks instead
+	queue *queueLimiter
+}
+
+// newExportMonitor wraps exporter with failure tracking
+func newExportMonitor(exporter sdktrace.SpanExporter) *exportMonitor {
+	return &exportMonitor{SpanExporter: exporter}
+}
+
+// ExportSpans implements sdktrace.SpanExporter
+func (m *exportMonitor) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	err := m.SpanExporter.ExportSpans(ctx, spans)
+	if err != nil {
+		m.failures.Add(1)
+		m.mu.Lock()
+		m.lastErr = err
+		m.mu.Unlock()
+		return err
+	}
+	m.failures.Store(0)
+	return nil
+}
+
+// lastError returns the most recent export error
+func (m *exportMonitor) lastError() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastErr
+}
+
+// ExportHealthFunc is notified when the export pipeline health changes
+type ExportHealthFunc func(healthy bool)
+
+// WatchExportHealth periodically checks the exporters and calls onChange when
+// sustained export failures to any of them begin or end. It blocks until ctx is done and
+// returns immediately when tracing is disabled.
+func (t *Tracer) WatchExportHealth(ctx context.Context, interval time.Duration, onChange ExportHealthFunc) {
+	if len(t.monitors) == 0 {
+		return
+	}
+
+	threshold := int64(t.exportFailureThreshold)
+	if threshold <= 0 {
+		threshold = defaultExportFailureThreshold
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	healthy := true
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			worst := t.worstMonitor()
+			failures := worst.failures.Load()
+			nowHealthy := failures < threshold
+			if nowHealthy == healthy {
+				continue
+			}
+			healthy = nowHealthy
+
+			if healthy {
+				t.logger.Info("Trace export recovered")
+			} else {
+				t.logger.Warn("Trace export unhealthy",
+					zap.Int64("consecutive_failures", failures),
+					zap.Error(worst.lastError()))
+			}
+			if onChange != nil {
+				onChange(healthy)
+			}
+		}
+	}
+}
+
+// worstMonitor returns the exporter monitor with the most consecutive failures
+func (t *Tracer) worstMonitor() *exportMonitor {
+	worst := t.monitors[0]
+	for _, m := range t.monitors[1:] {
+		if m.failures.Load() > worst.failures.Load() {
+			worst = m
+		}
+	}
+	return worst
+}
@@ -0,0 +1,91 @@
+package tracer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// failingExporter fails exports while fail is set
+type failingExporter struct {
+	mu   sync.Mutex
+	fail bool
+}
+
+func (e *failingExporter) setFail(fail bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.fail = fail
+}
+
+func (e *failingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.fail {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func (e *failingExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func TestTracer_WatchExportHealth(t *testing.T) {
+	// Create a test logger with observer
+	core, recorded := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	exporter := &failingExporter{fail: true}
+	monitor := newExportMonitor(exporter)
+	tracer := &Tracer{
+		logger:                 logger,
+		monitors:               []*exportMonitor{monitor},
+		exportFailureThreshold: 2,
+	}
+
+	changes := make(chan bool, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go tracer.WatchExportHealth(ctx, 10*time.Millisecond, func(healthy bool) {
+		changes <- healthy
+	})
+
+	// Simulate sustained export failures
+	for i := 0; i < 2; i++ {
+		_ = monitor.ExportSpans(ctx, nil)
+	}
+	select {
+	case healthy := <-changes:
+		if healthy {
+			t.Fatal("WatchExportHealth() reported healthy, expected unhealthy")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("WatchExportHealth() did not report the outage")
+	}
+	if recorded.FilterMessage("Trace export unhealthy").Len() == 0 {
+		t.Error("Expected to find 'Trace export unhealthy' log message")
+	}
+
+	// Simulate recovery
+	exporter.setFail(false)
+	_ = monitor.ExportSpans(ctx, nil)
+	select {
+	case healthy := <-changes:
+		if !healthy {
+			t.Fatal("WatchExportHealth() reported unhealthy, expected recovery")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("WatchExportHealth() did not report the recovery")
+	}
+	if recorded.FilterMessage("Trace export recovered").Len() == 0 {
+		t.Error("Expected to find 'Trace export recovered' log message")
+	}
+}
@@ -0,0 +1,59 @@
+package tracer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/jaeger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// defaultPropagatorFormat propagates W3C trace context and baggage
+const defaultPropagatorFormat = "tracecontext,baggage"
+
+// newPropagator returns the propagator registered by New for a comma-separated
+// list of formats, named as in OTEL_PROPAGATORS: tracecontext, baggage, b3
+// (single header), b3multi (X-B3-* headers) and jaeger (uber-trace-id).
+// An empty format uses W3C trace context and baggage.
+func newPropagator(format string) (propagation.TextMapPropagator, error) {
+	if strings.TrimSpace(format) == "" {
+		format = defaultPropagatorFormat
+	}
+
+	var propagators []propagation.TextMapPropagator
+	for _, name := range strings.Split(format, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "tracecontext":
+			propagators = append(propagators, propagation.TraceContext{})
+		case "baggage":
+			propagators = append(propagators, propagation.Baggage{})
+		case "b3":
+			propagators = append(propagators, b3.New())
+		case "b3multi":
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		case "jaeger":
+			propagators = append(propagators, jaeger.Jaeger{})
+		default:
+			return nil, fmt.Errorf("unknown propagator %q, expected tracecontext, baggage, b3, b3multi or jaeger", name)
+		}
+	}
+	return propagation.NewCompositeTextMapPropagator(propagators...), nil
+}
+
+// InjectMap returns the trace context carried by ctx as a string map, using
+// the registered propagator, so it can travel over transports other than
+// HTTP such as message headers or environment variables
+func InjectMap(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	return carrier
+}
+
+// ExtractMap returns a copy of ctx carrying the trace context read from a map
+// produced by InjectMap, using the registered propagator
+func ExtractMap(ctx context.Context, carrier map[string]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(carrier))
+}
@@ -0,0 +1,95 @@
+package tracer
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestInjectMap_ExtractMap(t *testing.T) {
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	// New registers the propagator used by InjectMap and ExtractMap
+	tracer, err := New(Config{
+		ServiceName:    "test-service",
+		FileExportPath: filepath.Join(t.TempDir(), "spans.jsonl"),
+	}, logger)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer func() { _ = tracer.Shutdown(context.Background()) }()
+
+	ctx, span := tracer.GetTracer().Start(context.Background(), "producer")
+	defer span.End()
+
+	carrier := InjectMap(ctx)
+	if carrier["traceparent"] == "" {
+		t.Fatalf("InjectMap() = %v, expected a traceparent entry", carrier)
+	}
+
+	extracted := trace.SpanContextFromContext(ExtractMap(context.Background(), carrier))
+	want := span.SpanContext()
+	if extracted.TraceID() != want.TraceID() {
+		t.Errorf("ExtractMap() trace ID = %s, expected %s", extracted.TraceID(), want.TraceID())
+	}
+	if extracted.SpanID() != want.SpanID() {
+		t.Errorf("ExtractMap() span ID = %s, expected %s", extracted.SpanID(), want.SpanID())
+	}
+	if !extracted.IsRemote() {
+		t.Error("ExtractMap() span context IsRemote() = false, expected true")
+	}
+}
+
+func TestExtractMap_Empty(t *testing.T) {
+	ctx := ExtractMap(context.Background(), map[string]string{})
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		t.Error("ExtractMap() of an empty map returned a valid span context")
+	}
+}
+
+func TestNewPropagator(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		expected []string
+	}{
+		{"default", "", []string{"traceparent", "tracestate", "baggage"}},
+		{"b3 single header", "b3", []string{"b3"}},
+		{"b3 multiple headers", "b3multi", []string{"x-b3-traceid", "x-b3-spanid", "x-b3-sampled", "x-b3-flags"}},
+		{"jaeger", "jaeger", []string{"uber-trace-id"}},
+		{"list", "tracecontext, b3", []string{"traceparent", "tracestate", "b3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			propagator, err := newPropagator(tt.format)
+			if err != nil {
+				t.Fatalf("newPropagator(%q) error = %v", tt.format, err)
+			}
+			fields := strings.Join(propagator.Fields(), ",")
+			for _, field := range tt.expected {
+				if !strings.Contains(fields, field) {
+					t.Errorf("newPropagator(%q) fields = %v, expected %s", tt.format, propagator.Fields(), field)
+				}
+			}
+		})
+	}
+}
+
+func TestNew_InvalidPropagatorFormat(t *testing.T) {
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	if _, err := New(Config{ServiceName: "test-service", Disabled: true, PropagatorFormat: "zipkin"}, logger); err == nil {
+		t.Error("New() error = nil, expected an error for an unknown propagator")
+	}
+}
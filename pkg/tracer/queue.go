@@ -0,0 +1,58 @@
+package tracer
+
+import (
+	"context"
+	"sync/atomic"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// queueLimiter mirrors the capacity of a batch processor's queue so spans
+// that would overflow it are counted as dropped rather than discarded
+// silently. A span occupies the queue from OnEnd until its batch has been
+// exported, which is slightly stricter than the processor's own accounting.
+type queueLimiter struct {
+	capacity int64
+	queued   atomic.Int64
+	dropped  atomic.Int64
+}
+
+// newQueueLimiter creates a limiter for a queue of capacity spans
+func newQueueLimiter(capacity int) *queueLimiter {
+	return &queueLimiter{capacity: int64(capacity)}
+}
+
+// limitedProcessor forwards ended spans to the batch processor while the
+// queue has room
+type limitedProcessor struct {
+	sdktrace.SpanProcessor
+	queue *queueLimiter
+}
+
+// OnEnd implements sdktrace.SpanProcessor
+func (p *limitedProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	// Unsampled spans are never queued by the batch processor
+	if !s.SpanContext().IsSampled() {
+		p.SpanProcessor.OnEnd(s)
+		return
+	}
+	if p.queue.queued.Add(1) > p.queue.capacity {
+		p.queue.queued.Add(-1)
+		p.queue.dropped.Add(1)
+		return
+	}
+	p.SpanProcessor.OnEnd(s)
+}
+
+// releasingExporter frees queue capacity once spans have been exported,
+// whether or not the export succeeded
+type releasingExporter struct {
+	sdktrace.SpanExporter
+	queue *queueLimiter
+}
+
+// ExportSpans implements sdktrace.SpanExporter
+func (e *releasingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	defer e.queue.queued.Add(-int64(len(spans)))
+	return e.SpanExporter.ExportSpans(ctx, spans)
+}
@@ -0,0 +1,69 @@
+package tracer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// slowExporter delays each export to back up the batch processor queue
+type slowExporter struct {
+	*tracetest.InMemoryExporter
+	delay time.Duration
+}
+
+func (e *slowExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	time.Sleep(e.delay)
+	return e.InMemoryExporter.ExportSpans(ctx, spans)
+}
+
+func TestNewTracerProvider_QueueFull(t *testing.T) {
+	res, err := newResource(Config{ServiceName: "test-service"})
+	if err != nil {
+		t.Fatalf("newResource() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		block bool
+	}{
+		{"drop", false},
+		{"block", true},
+	}
+
+	const spans = 20
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := &slowExporter{InMemoryExporter: tracetest.NewInMemoryExporter(), delay: 20 * time.Millisecond}
+			tp, monitors := newTracerProvider(Config{MaxQueueSize: 2, BlockOnQueueFull: tt.block}, res, []sdktrace.SpanExporter{exporter})
+			tracer := &Tracer{monitors: monitors}
+
+			for i := 0; i < spans; i++ {
+				_, span := tp.Tracer("test").Start(context.Background(), "test-span")
+				span.End()
+			}
+			if err := tp.ForceFlush(context.Background()); err != nil {
+				t.Fatalf("ForceFlush() error = %v", err)
+			}
+			defer func() { _ = tp.Shutdown(context.Background()) }()
+
+			exported := int64(len(exporter.GetSpans()))
+			dropped := tracer.DroppedSpans()
+			if tt.block {
+				if dropped != 0 || exported != spans {
+					t.Errorf("Blocking queue exported %d and dropped %d spans, expected %d and 0", exported, dropped, spans)
+				}
+				return
+			}
+			if dropped == 0 {
+				t.Error("DroppedSpans() = 0, expected drops with a full queue")
+			}
+			if exported+dropped != spans {
+				t.Errorf("Exported %d + dropped %d spans, expected %d in total", exported, dropped, spans)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package tracer
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// cycleSpans tracks the child spans started under a local root span
+type cycleSpans struct {
+	root     sdktrace.ReadWriteSpan
+	members  []trace.SpanID
+	children int
+	limited  bool
+}
+
+// spanLimiter caps the child spans of each local root span exported through
+// next. Children past the cap are still created and sampled, so the trace
+// context propagated to the target keeps its sampled flag and the target
+// keeps tracing, but they are never forwarded for export. The first capped
+// child adds a span_limit_reached event to its root.
+//
+// Cycles are keyed by root span ID rather than trace ID, so /trigger cycles
+// continuing the same caller trace each get their own allowance.
+type spanLimiter struct {
+	max  int
+	next sdktrace.SpanProcessor
+
+	mu sync.Mutex
+	// cycles maps every tracked span, root or descendant, to its cycle
+	cycles map[trace.SpanID]*cycleSpans
+	// capped holds the children withheld from export until they end
+	capped map[trace.SpanID]bool
+}
+
+// newSpanLimiter creates a limiter allowing max children per root span and
+// forwarding the rest to next
+func newSpanLimiter(max int, next sdktrace.SpanProcessor) *spanLimiter {
+	return &spanLimiter{
+		max:    max,
+		next:   next,
+		cycles: make(map[trace.SpanID]*cycleSpans),
+		capped: make(map[trace.SpanID]bool),
+	}
+}
+
+// isLocalRoot reports whether a span with the given parent is a local root
+func isLocalRoot(parent trace.SpanContext) bool {
+	return !parent.IsValid() || parent.IsRemote()
+}
+
+// OnStart implements sdktrace.SpanProcessor
+func (l *spanLimiter) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	spanID := s.SpanContext().SpanID()
+	if isLocalRoot(s.Parent()) {
+		l.mu.Lock()
+		l.cycles[spanID] = &cycleSpans{root: s}
+		l.mu.Unlock()
+		l.next.OnStart(parent, s)
+		return
+	}
+
+	l.mu.Lock()
+	cycle, ok := l.cycles[s.Parent().SpanID()]
+	if !ok {
+		l.mu.Unlock()
+		l.next.OnStart(parent, s)
+		return
+	}
+	l.cycles[spanID] = cycle
+	cycle.members = append(cycle.members, spanID)
+	cycle.children++
+	if cycle.children <= l.max {
+		l.mu.Unlock()
+		l.next.OnStart(parent, s)
+		return
+	}
+	l.capped[spanID] = true
+	first := !cycle.limited
+	cycle.limited = true
+	l.mu.Unlock()
+
+	if first {
+		cycle.root.AddEvent("span_limit_reached",
+			trace.WithAttributes(attribute.Int("span.limit", l.max)))
+	}
+}
+
+// OnEnd implements sdktrace.SpanProcessor
+func (l *spanLimiter) OnEnd(s sdktrace.ReadOnlySpan) {
+	spanID := s.SpanContext().SpanID()
+
+	l.mu.Lock()
+	if l.capped[spanID] {
+		delete(l.capped, spanID)
+		l.mu.Unlock()
+		return
+	}
+	if isLocalRoot(s.Parent()) {
+		if cycle, ok := l.cycles[spanID]; ok {
+			for _, member := range cycle.members {
+				delete(l.cycles, member)
+			}
+			delete(l.cycles, spanID)
+		}
+	}
+	l.mu.Unlock()
+
+	l.next.OnEnd(s)
+}
+
+// Shutdown implements sdktrace.SpanProcessor
+func (l *spanLimiter) Shutdown(ctx context.Context) error {
+	l.mu.Lock()
+	l.cycles = make(map[trace.SpanID]*cycleSpans)
+	l.capped = make(map[trace.SpanID]bool)
+	l.mu.Unlock()
+	return l.next.Shutdown(ctx)
+}
+
+// ForceFlush implements sdktrace.SpanProcessor
+func (l *spanLimiter) ForceFlush(ctx context.Context) error {
+	return l.next.ForceFlush(ctx)
+}
@@ -0,0 +1,201 @@
+package tracer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"tracer-test/pkg/httpclient"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+func TestNewTracerProvider_MaxSpansPerCycle(t *testing.T) {
+	res, err := newResource(Config{ServiceName: "test-service"})
+	if err != nil {
+		t.Fatalf("newResource() error = %v", err)
+	}
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp, _ := newTracerProvider(Config{MaxSpansPerCycle: 3}, res, []sdktrace.SpanExporter{exporter})
+	tracer := tp.Tracer("test")
+
+	// Start far more children, and grandchildren, than the cap allows
+	ctx, root := tracer.Start(context.Background(), "request.cycle")
+	for i := 0; i < 10; i++ {
+		childCtx, child := tracer.Start(ctx, "http.get.attempt")
+		_, grandchild := tracer.Start(childCtx, "dns.resolve")
+		grandchild.End()
+		child.End()
+	}
+	root.End()
+
+	// A new cycle gets a fresh allowance
+	ctx, next := tracer.Start(context.Background(), "request.cycle")
+	_, child := tracer.Start(ctx, "http.get")
+	child.End()
+	next.End()
+
+	if err := tp.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("ForceFlush() error = %v", err)
+	}
+
+	counts := map[string]int{}
+	var limitEvents int
+	for _, s := range exporter.GetSpans() {
+		counts[s.Name]++
+		for _, event := range s.Events {
+			if event.Name == "span_limit_reached" {
+				limitEvents++
+				if s.Name != "request.cycle" {
+					t.Errorf("span_limit_reached recorded on %s, expected the root span", s.Name)
+				}
+			}
+		}
+	}
+	if children := counts["http.get.attempt"] + counts["dns.resolve"]; children != 3 {
+		t.Errorf("Exported %d child spans of the first cycle, expected 3", children)
+	}
+	if counts["request.cycle"] != 2 || counts["http.get"] != 1 {
+		t.Errorf("Exported spans = %v, expected both roots and the second cycle's child", counts)
+	}
+	if limitEvents != 1 {
+		t.Errorf("span_limit_reached events = %d, expected 1", limitEvents)
+	}
+}
+
+func TestNewTracerProvider_MaxSpansPerCycle_Retries(t *testing.T) {
+	// Propagate W3C trace context, restoring the previous propagator
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(previous)
+
+	// Create a test server that always fails, recording each traceparent
+	var (
+		mu           sync.Mutex
+		traceparents []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		traceparents = append(traceparents, r.Header.Get("Traceparent"))
+		mu.Unlock()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	res, err := newResource(Config{ServiceName: "test-service"})
+	if err != nil {
+		t.Fatalf("newResource() error = %v", err)
+	}
+	exporter := tracetest.NewInMemoryExporter()
+	tp, _ := newTracerProvider(Config{MaxSpansPerCycle: 3}, res, []sdktrace.SpanExporter{exporter})
+	tracer := tp.Tracer("test")
+
+	// Each retry adds attempt and transport spans well past the cap
+	client := httpclient.New(httpclient.Config{
+		Timeout:      5 * time.Second,
+		MaxRetries:   6,
+		RetryBackoff: time.Millisecond,
+	}, zap.NewNop(), tracer)
+	defer client.Close()
+
+	ctx, root := tracer.Start(context.Background(), "request.cycle")
+	resp, err := client.Get(ctx, server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	root.End()
+
+	if err := tp.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("ForceFlush() error = %v", err)
+	}
+
+	// The request completes with every attempt sent
+	if len(traceparents) != 7 {
+		t.Fatalf("Server saw %d requests, expected 7", len(traceparents))
+	}
+
+	children := 0
+	limited := false
+	for _, s := range exporter.GetSpans() {
+		if s.Name != "request.cycle" {
+			children++
+			continue
+		}
+		for _, event := range s.Events {
+			if event.Name == "span_limit_reached" {
+				limited = true
+			}
+		}
+	}
+	if children != 3 {
+		t.Errorf("Exported %d child spans, expected 3", children)
+	}
+	if !limited {
+		t.Error("Expected a span_limit_reached event on request.cycle")
+	}
+
+	// Capped attempts still tell the target to sample
+	for i, traceparent := range traceparents {
+		if !strings.HasSuffix(traceparent, "-01") {
+			t.Errorf("Attempt %d traceparent = %q, expected the sampled flag", i+1, traceparent)
+		}
+	}
+}
+
+func TestNewTracerProvider_MaxSpansPerCycle_SharedTrace(t *testing.T) {
+	res, err := newResource(Config{ServiceName: "test-service"})
+	if err != nil {
+		t.Fatalf("newResource() error = %v", err)
+	}
+	exporter := tracetest.NewInMemoryExporter()
+	tp, _ := newTracerProvider(Config{MaxSpansPerCycle: 2}, res, []sdktrace.SpanExporter{exporter})
+	tracer := tp.Tracer("test")
+
+	// Two /trigger cycles continue the same caller trace
+	caller := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	parent := trace.ContextWithRemoteSpanContext(context.Background(), caller)
+	firstCtx, first := tracer.Start(parent, "request.cycle")
+	secondCtx, second := tracer.Start(parent, "request.cycle")
+	for i := 0; i < 2; i++ {
+		_, child := tracer.Start(firstCtx, "http.get")
+		child.End()
+		_, child = tracer.Start(secondCtx, "http.get")
+		child.End()
+	}
+	first.End()
+	second.End()
+
+	if err := tp.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("ForceFlush() error = %v", err)
+	}
+
+	// Each cycle gets its own allowance
+	children := 0
+	for _, s := range exporter.GetSpans() {
+		if s.Name == "http.get" {
+			children++
+		}
+		if len(s.Events) > 0 {
+			t.Errorf("%s has events %v, expected no cycle to reach the limit", s.Name, s.Events)
+		}
+	}
+	if children != 4 {
+		t.Errorf("Exported %d child spans, expected 4", children)
+	}
+}
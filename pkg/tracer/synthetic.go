@@ -0,0 +1,36 @@
+package tracer
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// syntheticAttributes flag traffic as synthetic so backends can keep it out
+// of real RED metrics. user_agent.synthetic.type is the semantic convention
+// vendors map onto their own synthetic markers.
+var syntheticAttributes = []attribute.KeyValue{
+	attribute.Bool("synthetic", true),
+	semconv.UserAgentSyntheticTypeTest,
+}
+
+// syntheticProcessor stamps every local root span with syntheticAttributes
+type syntheticProcessor struct{}
+
+// OnStart implements sdktrace.SpanProcessor
+func (syntheticProcessor) OnStart(_ context.Context, s sdktrace.ReadWriteSpan) {
+	if !s.Parent().IsValid() || s.Parent().IsRemote() {
+		s.SetAttributes(syntheticAttributes...)
+	}
+}
+
+// OnEnd implements sdktrace.SpanProcessor
+func (syntheticProcessor) OnEnd(sdktrace.ReadOnlySpan) {}
+
+// Shutdown implements sdktrace.SpanProcessor
+func (syntheticProcessor) Shutdown(context.Context) error { return nil }
+
+// ForceFlush implements sdktrace.SpanProcessor
+func (syntheticProcessor) ForceFlush(context.Context) error { return nil }
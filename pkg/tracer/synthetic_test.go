@@ -0,0 +1,71 @@
+package tracer
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestNew_Synthetic(t *testing.T) {
+	tests := []struct {
+		name      string
+		synthetic bool
+	}{
+		{"enabled", true},
+		{"disabled", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a test logger
+			core, _ := observer.New(zapcore.InfoLevel)
+			logger := zap.New(core)
+
+			recorder := tracetest.NewSpanRecorder()
+			tracer, err := New(Config{
+				ServiceName:         "test-service",
+				Synthetic:           tt.synthetic,
+				FileExportPath:      filepath.Join(t.TempDir(), "spans.jsonl"),
+				ExtraSpanProcessors: []sdktrace.SpanProcessor{recorder},
+			}, logger)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer func() { _ = tracer.Shutdown(context.Background()) }()
+
+			ctx, root := tracer.GetTracer().Start(context.Background(), "root")
+			_, child := tracer.GetTracer().Start(ctx, "child")
+			child.End()
+			root.End()
+
+			for _, s := range recorder.Ended() {
+				expected := tt.synthetic && s.Name() == "root"
+				if got := hasAttribute(s, "synthetic"); got != expected {
+					t.Errorf("Span %s has synthetic = %v, expected %v", s.Name(), got, expected)
+				}
+				if got := hasAttribute(s, "user_agent.synthetic.type"); got != expected {
+					t.Errorf("Span %s has user_agent.synthetic.type = %v, expected %v", s.Name(), got, expected)
+				}
+				if _, ok := s.Resource().Set().Value("synthetic"); ok != tt.synthetic {
+					t.Errorf("Resource has synthetic = %v, expected %v", ok, tt.synthetic)
+				}
+			}
+		})
+	}
+}
+
+// hasAttribute reports whether the span carries the attribute key
+func hasAttribute(s sdktrace.ReadOnlySpan, key string) bool {
+	for _, attr := range s.Attributes() {
+		if string(attr.Key) == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package tracer
+
+import (
+	"context"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// ExportDurationFunc is called with the time each span export took
+type ExportDurationFunc func(duration time.Duration)
+
+// timedExporter wraps an exporter and reports how long each export takes
+type timedExporter struct {
+	sdktrace.SpanExporter
+
+	observe ExportDurationFunc
+}
+
+// newTimedExporter wraps exporter so observe sees every export's duration
+func newTimedExporter(exporter sdktrace.SpanExporter, observe ExportDurationFunc) *timedExporter {
+	return &timedExporter{SpanExporter: exporter, observe: observe}
+}
+
+// ExportSpans implements sdktrace.SpanExporter
+func (e *timedExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	start := time.Now()
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+	e.observe(time.Since(start))
+	return err
+}
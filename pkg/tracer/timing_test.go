@@ -0,0 +1,51 @@
+package tracer
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNewTracerProvider_ObserveExportDuration(t *testing.T) {
+	res, err := newResource(Config{ServiceName: "test-service"})
+	if err != nil {
+		t.Fatalf("newResource() error = %v", err)
+	}
+
+	// Record every export duration reported by the wrapper
+	var (
+		mu        sync.Mutex
+		durations []time.Duration
+	)
+	config := Config{ObserveExportDuration: func(duration time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		durations = append(durations, duration)
+	}}
+
+	const delay = 50 * time.Millisecond
+	exporter := &slowExporter{InMemoryExporter: tracetest.NewInMemoryExporter(), delay: delay}
+	tp, _ := newTracerProvider(config, res, []sdktrace.SpanExporter{exporter})
+
+	_, span := tp.Tracer("test").Start(context.Background(), "test-span")
+	span.End()
+	if err := tp.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("ForceFlush() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(durations) != 1 {
+		t.Fatalf("Observed %d exports, expected 1", len(durations))
+	}
+	if durations[0] < delay || durations[0] > 10*delay {
+		t.Errorf("Export duration = %s, expected about %s", durations[0], delay)
+	}
+	if got := len(exporter.GetSpans()); got != 1 {
+		t.Errorf("Exported %d spans, expected 1", got)
+	}
+}
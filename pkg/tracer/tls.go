@@ -0,0 +1,27 @@
+package tracer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// newExporterTLSConfig builds the TLS configuration for OTLP exporters,
+// trusting the CA certificates in caCertPath in place of the system pool.
+// It returns nil when caCertPath is empty.
+func newExporterTLSConfig(caCertPath string) (*tls.Config, error) {
+	if caCertPath == "" {
+		return nil, nil
+	}
+
+	pem, err := os.ReadFile(caCertPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OTLP CA certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("failed to parse OTLP CA certificate %s: no PEM certificates found", caCertPath)
+	}
+	return &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}, nil
+}
@@ -0,0 +1,100 @@
+package tracer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// writeTestCA writes a self-signed CA certificate to a PEM file
+func writeTestCA(t *testing.T) string {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestNewOTLPExporter_CACert(t *testing.T) {
+	tlsConfig, err := newExporterTLSConfig(writeTestCA(t))
+	if err != nil {
+		t.Fatalf("newExporterTLSConfig() error = %v", err)
+	}
+	if tlsConfig == nil || tlsConfig.RootCAs == nil {
+		t.Fatal("newExporterTLSConfig() expected a config with RootCAs")
+	}
+	if tlsConfig.InsecureSkipVerify {
+		t.Error("newExporterTLSConfig() expected verification to stay enabled")
+	}
+
+	exporter, err := newOTLPExporter("https://collector.example.com:4318", tlsConfig)
+	if err != nil {
+		t.Fatalf("newOTLPExporter() error = %v", err)
+	}
+	_ = exporter.Shutdown(context.Background())
+}
+
+func TestNew_InvalidCACert(t *testing.T) {
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"missing file", filepath.Join(t.TempDir(), "missing.pem")},
+		{"not PEM", notPEM},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(Config{
+				Endpoint:      "https://collector.example.com:4318",
+				ServiceName:   "test-service",
+				TLSCACertPath: tt.path,
+			}, logger)
+			if err == nil {
+				t.Error("New() error = nil, expected an error")
+			}
+		})
+	}
+}
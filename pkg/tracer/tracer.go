@@ -2,52 +2,277 @@ package tracer
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 	"go.uber.org/zap"
 )
 
 // Config holds tracer configuration
 type Config struct {
-	Endpoint    string
-	ServiceName string
-	Disabled    bool
+	Endpoint string
+	// Endpoints fans spans out to several OTLP endpoints, each with its own
+	// batch processor and queue so a slow or unreachable endpoint does not
+	// hold up the others. When empty, Endpoint is used.
+	Endpoints []string
+	// TLSCACertPath is a PEM bundle of CA certificates trusted when exporting
+	// to https endpoints, for collectors behind a private CA. Verification
+	// stays enabled; plain http endpoints are unaffected.
+	TLSCACertPath string
+	ServiceName   string
+	Disabled      bool
+	// Environment and Region, when set, are recorded as the
+	// deployment.environment and cloud.region resource attributes
+	Environment string
+	Region      string
+	// Synthetic marks the traffic as synthetic monitoring: every root span
+	// and the resource carry synthetic=true so backends can filter it out
+	Synthetic bool
+	// IncludeVCSInfo adds the vcs.revision, vcs.time and vcs.modified
+	// resource attributes from the binary's build information, when the
+	// build recorded them
+	IncludeVCSInfo bool
+	// InstanceID is recorded as service.instance.id. When empty, a random
+	// UUID is generated once per process.
+	InstanceID string
+	// FileExportPath writes spans as JSON lines to the given file instead of
+	// exporting them via OTLP. The file is appended to and never rotated or
+	// truncated; remove or rotate it externally between runs if needed.
+	FileExportPath string
+	// ExportOnErrorOnly buffers the spans of each request cycle in memory and
+	// exports them only when the cycle contains an error or its root span
+	// lasted at least SlowCycleThreshold. This trades memory for volume.
+	ExportOnErrorOnly  bool
+	SlowCycleThreshold time.Duration
+	// MinSpanDuration, when positive, buffers the spans of each request
+	// cycle like ExportOnErrorOnly and exports them only when the cycle's
+	// root span lasted longer than this, failed or not. With
+	// ExportOnErrorOnly, a cycle must satisfy both to be exported.
+	MinSpanDuration time.Duration
+	// ExportFailureThreshold is the number of consecutive failed exports
+	// after which WatchExportHealth reports the pipeline unhealthy.
+	// Defaults to 3.
+	ExportFailureThreshold int
+	// MaxQueueSize is the number of spans each exporter's batch processor
+	// queues before export. Defaults to 2048. Spans arriving at a full queue
+	// are dropped and counted by DroppedSpans, unless BlockOnQueueFull is
+	// set: then ending a span waits for room, applying backpressure that
+	// adds latency to the request loop while the exporter is slow.
+	MaxQueueSize     int
+	BlockOnQueueFull bool
+	// ObserveExportDuration, when set, is called with the time each call to
+	// an exporter's ExportSpans took, failed or not, to expose the cost of a
+	// slow collector.
+	ObserveExportDuration ExportDurationFunc
+	// PropagatorFormat is a comma-separated list of the trace context formats
+	// injected into outgoing requests and extracted by ExtractMap:
+	// tracecontext, baggage, b3 (single header), b3multi (X-B3-* headers) or
+	// jaeger (uber-trace-id). Defaults to "tracecontext,baggage".
+	PropagatorFormat string
+	// Sampler decides which traces are recorded and exported. Defaults to
+	// sampling every trace that has no sampled parent.
+	Sampler sdktrace.Sampler
+	// MaxSpansPerCycle, when positive, caps the child spans exported under
+	// each local root span such as request.cycle. Further children are not
+	// exported, and the root gets a span_limit_reached event, while the
+	// request itself completes normally. Capped children stay sampled, so
+	// the target keeps tracing, but its spans then hang off a parent that
+	// was never exported.
+	MaxSpansPerCycle int
+	// ExtraSpanProcessors are registered after the exporting processors, e.g.
+	// to scrub attributes or record spans in tests. Processors run in
+	// registration order, so these see spans after they are queued for export.
+	ExtraSpanProcessors []sdktrace.SpanProcessor
 }
 
+// processInstanceID is generated once so every tracer in the process shares it
+var processInstanceID = uuid.NewString()
+
 // Tracer wraps the OpenTelemetry tracer
 type Tracer struct {
-	tracer trace.Tracer
-	logger *zap.Logger
+	tracer   trace.Tracer
+	logger   *zap.Logger
+	file     *os.File
+	monitors []*exportMonitor
+
+	exportFailureThreshold int
 }
 
 // New creates a new tracer instance
 func New(config Config, logger *zap.Logger) (*Tracer, error) {
+	propagator, err := newPropagator(config.PropagatorFormat)
+	if err != nil {
+		return nil, err
+	}
+
 	if config.Disabled {
 		logger.Info("OTLP tracing disabled - using no-op tracer")
 		// Return a no-op tracer
-		noopTracer := otel.Tracer("noop")
+		noopTracer := noop.NewTracerProvider().Tracer("noop")
 		return &Tracer{
 			tracer: noopTracer,
 			logger: logger,
 		}, nil
 	}
 
-	logger.Info("Initializing OTLP tracer",
-		zap.String("otlp_endpoint", config.Endpoint),
-		zap.String("service_name", config.ServiceName))
+	var (
+		exporters []sdktrace.SpanExporter
+		file      *os.File
+	)
+	if config.FileExportPath != "" {
+		logger.Info("Initializing file trace exporter",
+			zap.String("file_export_path", config.FileExportPath),
+			zap.String("service_name", config.ServiceName))
+
+		exporter, f, err := newFileExporter(config.FileExportPath)
+		if err != nil {
+			return nil, err
+		}
+		exporters = append(exporters, exporter)
+		file = f
+	} else {
+		tlsConfig, err := newExporterTLSConfig(config.TLSCACertPath)
+		if err != nil {
+			return nil, err
+		}
+
+		endpoints := config.Endpoints
+		if len(endpoints) == 0 {
+			endpoints = []string{config.Endpoint}
+		}
+		for _, endpoint := range endpoints {
+			logger.Info("Initializing OTLP tracer",
+				zap.String("otlp_endpoint", endpoint),
+				zap.String("service_name", config.ServiceName))
+
+			exporter, err := newOTLPExporter(endpoint, tlsConfig)
+			if err != nil {
+				return nil, err
+			}
+			exporters = append(exporters, exporter)
+		}
+	}
+
+	// Create resource
+	res, err := newResource(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create trace provider
+	tp, monitors := newTracerProvider(config, res, exporters)
+
+	// Set global tracer provider
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagator)
+
+	// Create tracer
+	tracer := tp.Tracer(config.ServiceName)
+
+	logger.Info("OTLP tracer initialized successfully")
+
+	return &Tracer{
+		tracer:   tracer,
+		logger:   logger,
+		file:     file,
+		monitors: monitors,
+
+		exportFailureThreshold: config.ExportFailureThreshold,
+	}, nil
+}
+
+// newTracerProvider creates one batch span processor per exporter and fans
+// spans out to all of them behind a single buffering processor, returning
+// the provider and each exporter's monitor
+func newTracerProvider(config Config, res *resource.Resource, exporters []sdktrace.SpanExporter) (*sdktrace.TracerProvider, []*exportMonitor) {
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	if config.Sampler != nil {
+		opts = append(opts, sdktrace.WithSampler(config.Sampler))
+	}
+	monitors := make([]*exportMonitor, 0, len(exporters))
+	queueSize := config.MaxQueueSize
+	if queueSize <= 0 {
+		queueSize = sdktrace.DefaultMaxQueueSize
+	}
+
+	// Stamp root spans before any exporting processor sees them
+	if config.Synthetic {
+		opts = append(opts, sdktrace.WithSpanProcessor(syntheticProcessor{}))
+	}
+
+	processors := make(fanoutProcessor, 0, len(exporters))
+	for _, exporter := range exporters {
+		// Time each export
+		if config.ObserveExportDuration != nil {
+			exporter = newTimedExporter(exporter, config.ObserveExportDuration)
+		}
+
+		// Track export failures
+		monitor := newExportMonitor(exporter)
+		monitors = append(monitors, monitor)
+
+		// Create span processor
+		var processor sdktrace.SpanProcessor
+		if config.BlockOnQueueFull {
+			processor = sdktrace.NewBatchSpanProcessor(monitor,
+				sdktrace.WithMaxQueueSize(queueSize), sdktrace.WithBlocking())
+		} else {
+			// Count the spans a full queue would drop
+			monitor.queue = newQueueLimiter(queueSize)
+			batch := sdktrace.NewBatchSpanProcessor(&releasingExporter{SpanExporter: monitor, queue: monitor.queue},
+				sdktrace.WithMaxQueueSize(queueSize))
+			processor = &limitedProcessor{SpanProcessor: batch, queue: monitor.queue}
+		}
+		processors = append(processors, processor)
+	}
+
+	// Buffer each cycle once in front of all exporters
+	if len(processors) > 0 {
+		var processor sdktrace.SpanProcessor = processors
+		var keeps []keepFunc
+		if config.ExportOnErrorOnly {
+			keeps = append(keeps, keepErrorOrSlow(config.SlowCycleThreshold))
+		}
+		if config.MinSpanDuration > 0 {
+			keeps = append(keeps, keepSlowerThan(config.MinSpanDuration))
+		}
+		if len(keeps) > 0 {
+			processor = newBufferingProcessor(processor, keepAll(keeps...))
+		}
+		if config.MaxSpansPerCycle > 0 {
+			processor = newSpanLimiter(config.MaxSpansPerCycle, processor)
+		}
+		opts = append(opts, sdktrace.WithSpanProcessor(processor))
+	}
 
+	for _, processor := range config.ExtraSpanProcessors {
+		opts = append(opts, sdktrace.WithSpanProcessor(processor))
+	}
+
+	return sdktrace.NewTracerProvider(opts...), monitors
+}
+
+// newOTLPExporter creates an OTLP HTTP exporter for the given endpoint,
+// using tlsConfig for https endpoints when it is not nil
+func newOTLPExporter(endpoint string, tlsConfig *tls.Config) (sdktrace.SpanExporter, error) {
 	// Parse the endpoint URL to determine if we should use insecure connection
-	useInsecure := shouldUseInsecure(config.Endpoint)
+	useInsecure := shouldUseInsecure(endpoint)
 
 	// Clean the endpoint URL (remove http:// or https:// prefix)
-	cleanEndpoint := cleanEndpointURL(config.Endpoint)
+	cleanEndpoint := cleanEndpointURL(endpoint)
 
 	// Build exporter options
 	opts := []otlptracehttp.Option{
@@ -58,6 +283,8 @@ func New(config Config, logger *zap.Logger) (*Tracer, error) {
 	// Add insecure option if needed
 	if useInsecure {
 		opts = append(opts, otlptracehttp.WithInsecure())
+	} else if tlsConfig != nil {
+		opts = append(opts, otlptracehttp.WithTLSClientConfig(tlsConfig))
 	}
 
 	// Create OTLP HTTP exporter
@@ -65,36 +292,66 @@ func New(config Config, logger *zap.Logger) (*Tracer, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
 	}
+	return exporter, nil
+}
 
-	// Create resource
-	res, err := resource.New(context.Background(),
-		resource.WithAttributes(
-			semconv.ServiceNameKey.String(config.ServiceName),
-			semconv.ServiceVersionKey.String("1.0.0"),
-		),
-	)
+// newFileExporter creates an exporter writing JSON-lines spans to path
+func newFileExporter(path string) (sdktrace.SpanExporter, *os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create resource: %w", err)
+		return nil, nil, fmt.Errorf("failed to open trace export file: %w", err)
 	}
 
-	// Create trace provider
-	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(exporter),
-		sdktrace.WithResource(res),
-	)
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, fmt.Errorf("failed to create file exporter: %w", err)
+	}
+	return exporter, file, nil
+}
 
-	// Set global tracer provider
-	otel.SetTracerProvider(tp)
+// newResource builds the resource describing this service
+func newResource(config Config) (*resource.Resource, error) {
+	instanceID := config.InstanceID
+	if instanceID == "" {
+		instanceID = processInstanceID
+	}
 
-	// Create tracer
-	tracer := tp.Tracer(config.ServiceName)
+	attrs := []attribute.KeyValue{
+		semconv.ServiceNameKey.String(config.ServiceName),
+		semconv.ServiceVersionKey.String("1.0.0"),
+		semconv.ServiceInstanceID(instanceID),
+	}
+	if config.Environment != "" {
+		attrs = append(attrs, attribute.String("deployment.environment", config.Environment))
+	}
+	if config.Region != "" {
+		attrs = append(attrs, semconv.CloudRegion(config.Region))
+	}
+	if config.Synthetic {
+		attrs = append(attrs, attribute.Bool("synthetic", true))
+	}
+	if config.IncludeVCSInfo {
+		attrs = append(attrs, vcsAttributes()...)
+	}
 
-	logger.Info("OTLP tracer initialized successfully")
+	res, err := resource.New(context.Background(), resource.WithAttributes(attrs...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resource: %w", err)
+	}
+	return res, nil
+}
 
-	return &Tracer{
-		tracer: tracer,
-		logger: logger,
-	}, nil
+// DroppedSpans returns the number of spans dropped because an export queue
+// was full. It is always zero with BlockOnQueueFull.
+func (t *Tracer) DroppedSpans() int64 {
+	var dropped int64
+	for _, m := range t.monitors {
+		if m.queue != nil {
+			dropped += m.queue.dropped.Load()
+		}
+	}
+	return dropped
 }
 
 // GetTracer returns the underlying tracer
@@ -144,7 +401,12 @@ func (t *Tracer) Shutdown(ctx context.Context) error {
 		return nil
 	}
 	if sdkTp, ok := tp.(*sdktrace.TracerProvider); ok {
-		return sdkTp.Shutdown(ctx)
+		if err := sdkTp.Shutdown(ctx); err != nil {
+			return err
+		}
+	}
+	if t.file != nil {
+		return t.file.Close()
 	}
 	return nil
 }
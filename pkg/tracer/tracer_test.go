@@ -2,8 +2,16 @@ package tracer
 
 import (
 	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest/observer"
@@ -226,3 +234,177 @@ func TestTracer_Shutdown(t *testing.T) {
 		t.Errorf("Shutdown() error = %v", err)
 	}
 }
+
+func TestNewResource_InstanceID(t *testing.T) {
+	// Test that an instance id is generated when none is configured
+	res, err := newResource(Config{ServiceName: "test-service"})
+	if err != nil {
+		t.Fatalf("newResource() error = %v", err)
+	}
+	value, ok := res.Set().Value(semconv.ServiceInstanceIDKey)
+	if !ok || value.AsString() == "" {
+		t.Error("newResource() expected a generated service.instance.id")
+	}
+
+	// Test that an explicit instance id is honored
+	res, err = newResource(Config{ServiceName: "test-service", InstanceID: "instance-1"})
+	if err != nil {
+		t.Fatalf("newResource() error = %v", err)
+	}
+	value, ok = res.Set().Value(semconv.ServiceInstanceIDKey)
+	if !ok || value.AsString() != "instance-1" {
+		t.Errorf("newResource() service.instance.id = %q, expected %q", value.AsString(), "instance-1")
+	}
+}
+
+func TestNewResource_EnvironmentAndRegion(t *testing.T) {
+	res, err := newResource(Config{ServiceName: "test-service", Environment: "staging", Region: "eu-west-1"})
+	if err != nil {
+		t.Fatalf("newResource() error = %v", err)
+	}
+	if value, ok := res.Set().Value("deployment.environment"); !ok || value.AsString() != "staging" {
+		t.Errorf("newResource() deployment.environment = %q, expected %q", value.AsString(), "staging")
+	}
+	if value, ok := res.Set().Value(semconv.CloudRegionKey); !ok || value.AsString() != "eu-west-1" {
+		t.Errorf("newResource() cloud.region = %q, expected %q", value.AsString(), "eu-west-1")
+	}
+
+	// Test that empty values are omitted
+	res, err = newResource(Config{ServiceName: "test-service"})
+	if err != nil {
+		t.Fatalf("newResource() error = %v", err)
+	}
+	if _, ok := res.Set().Value("deployment.environment"); ok {
+		t.Error("newResource() expected no deployment.environment when empty")
+	}
+	if _, ok := res.Set().Value(semconv.CloudRegionKey); ok {
+		t.Error("newResource() expected no cloud.region when empty")
+	}
+}
+
+func TestNew_FileExport(t *testing.T) {
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	path := filepath.Join(t.TempDir(), "spans.jsonl")
+	config := Config{
+		ServiceName:    "test-service",
+		FileExportPath: path,
+	}
+
+	tracer, err := New(config, logger)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// End a span and flush it to the file
+	_, span := tracer.GetTracer().Start(context.Background(), "test-span")
+	span.End()
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read export file: %v", err)
+	}
+
+	// Check that the first line is a parseable span record
+	line, _, _ := strings.Cut(string(data), "\n")
+	var record struct {
+		Name string `json:"Name"`
+	}
+	if err := json.Unmarshal([]byte(line), &record); err != nil {
+		t.Fatalf("Failed to parse span record %q: %v", line, err)
+	}
+	if record.Name != "test-span" {
+		t.Errorf("Span record name = %q, expected %q", record.Name, "test-span")
+	}
+}
+
+func TestNewTracerProvider_FanOut(t *testing.T) {
+	res, err := newResource(Config{ServiceName: "test-service"})
+	if err != nil {
+		t.Fatalf("newResource() error = %v", err)
+	}
+
+	// One exporter is down; the other must still receive spans
+	down := &failingExporter{fail: true}
+	first := tracetest.NewInMemoryExporter()
+	second := tracetest.NewInMemoryExporter()
+
+	tp, monitors := newTracerProvider(Config{}, res, []sdktrace.SpanExporter{first, second, down})
+	if len(monitors) != 3 {
+		t.Fatalf("newTracerProvider() returned %d monitors, expected 3", len(monitors))
+	}
+
+	_, span := tp.Tracer("test").Start(context.Background(), "test-span")
+	span.End()
+	if err := tp.ForceFlush(context.Background()); err == nil {
+		t.Error("ForceFlush() error = nil, expected the failing exporter's error")
+	}
+
+	for i, exporter := range []*tracetest.InMemoryExporter{first, second} {
+		spans := exporter.GetSpans()
+		if len(spans) != 1 || spans[0].Name != "test-span" {
+			t.Errorf("Exporter %d received %d spans, expected test-span", i+1, len(spans))
+		}
+	}
+	if failures := monitors[2].failures.Load(); failures != 1 {
+		t.Errorf("Failing exporter failures = %d, expected 1", failures)
+	}
+}
+
+func TestNewTracerProvider_BufferedFanOut(t *testing.T) {
+	res, err := newResource(Config{ServiceName: "test-service"})
+	if err != nil {
+		t.Fatalf("newResource() error = %v", err)
+	}
+
+	// One buffering processor decides for both exporters
+	first := tracetest.NewInMemoryExporter()
+	second := tracetest.NewInMemoryExporter()
+	tp, _ := newTracerProvider(Config{ExportOnErrorOnly: true}, res, []sdktrace.SpanExporter{first, second})
+	tracer := tp.Tracer("test")
+
+	_, ok := tracer.Start(context.Background(), "request.cycle")
+	ok.End()
+	_, failed := tracer.Start(context.Background(), "request.cycle")
+	failed.SetStatus(codes.Error, "connection refused")
+	failed.End()
+	if err := tp.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("ForceFlush() error = %v", err)
+	}
+
+	for i, exporter := range []*tracetest.InMemoryExporter{first, second} {
+		if n := len(exporter.GetSpans()); n != 1 {
+			t.Errorf("Exporter %d received %d spans, expected only the failed cycle", i+1, n)
+		}
+	}
+}
+
+func TestNew_ExtraSpanProcessors(t *testing.T) {
+	// Create a test logger
+	core, _ := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer, err := New(Config{
+		ServiceName:         "test-service",
+		FileExportPath:      filepath.Join(t.TempDir(), "spans.jsonl"),
+		ExtraSpanProcessors: []sdktrace.SpanProcessor{recorder},
+	}, logger)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer func() { _ = tracer.Shutdown(context.Background()) }()
+
+	_, span := tracer.GetTracer().Start(context.Background(), "test-span")
+	span.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "test-span" {
+		t.Errorf("Recording processor observed %d spans, expected test-span", len(spans))
+	}
+}
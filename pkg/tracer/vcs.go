@@ -0,0 +1,35 @@
+package tracer
+
+import (
+	"runtime/debug"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// readBuildInfo reads the build information embedded in the binary
+var readBuildInfo = debug.ReadBuildInfo
+
+// vcsAttributes returns the vcs.revision, vcs.time and vcs.modified
+// resource attributes from the binary's build information. Settings the
+// build did not record, e.g. in binaries built outside a repository or with
+// -buildvcs=false, are omitted.
+func vcsAttributes() []attribute.KeyValue {
+	build, ok := readBuildInfo()
+	if !ok {
+		return nil
+	}
+
+	var attrs []attribute.KeyValue
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision", "vcs.time":
+			attrs = append(attrs, attribute.String(setting.Key, setting.Value))
+		case "vcs.modified":
+			if modified, err := strconv.ParseBool(setting.Value); err == nil {
+				attrs = append(attrs, attribute.Bool(setting.Key, modified))
+			}
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,57 @@
+package tracer
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestNewResource_IncludeVCSInfo(t *testing.T) {
+	defer func(read func() (*debug.BuildInfo, bool)) { readBuildInfo = read }(readBuildInfo)
+
+	// Stub build information as recorded by go build in a repository
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{Settings: []debug.BuildSetting{
+			{Key: "-compiler", Value: "gc"},
+			{Key: "vcs", Value: "git"},
+			{Key: "vcs.revision", Value: "0123456789abcdef"},
+			{Key: "vcs.time", Value: "2024-01-15T10:30:45Z"},
+			{Key: "vcs.modified", Value: "true"},
+		}}, true
+	}
+
+	res, err := newResource(Config{ServiceName: "test-service", IncludeVCSInfo: true})
+	if err != nil {
+		t.Fatalf("newResource() error = %v", err)
+	}
+	if value, ok := res.Set().Value("vcs.revision"); !ok || value.AsString() != "0123456789abcdef" {
+		t.Errorf("newResource() vcs.revision = %q, expected %q", value.AsString(), "0123456789abcdef")
+	}
+	if value, ok := res.Set().Value("vcs.time"); !ok || value.AsString() != "2024-01-15T10:30:45Z" {
+		t.Errorf("newResource() vcs.time = %q, expected %q", value.AsString(), "2024-01-15T10:30:45Z")
+	}
+	if value, ok := res.Set().Value("vcs.modified"); !ok || !value.AsBool() {
+		t.Errorf("newResource() vcs.modified = %v, expected true", value.AsBool())
+	}
+	if _, ok := res.Set().Value("vcs"); ok {
+		t.Error("newResource() expected only vcs.* settings to be recorded")
+	}
+
+	// Test that the attributes are omitted without the option
+	res, err = newResource(Config{ServiceName: "test-service"})
+	if err != nil {
+		t.Fatalf("newResource() error = %v", err)
+	}
+	if _, ok := res.Set().Value("vcs.revision"); ok {
+		t.Error("newResource() expected no vcs.revision without IncludeVCSInfo")
+	}
+
+	// Test that missing build information is tolerated
+	readBuildInfo = func() (*debug.BuildInfo, bool) { return nil, false }
+	res, err = newResource(Config{ServiceName: "test-service", IncludeVCSInfo: true})
+	if err != nil {
+		t.Fatalf("newResource() error = %v", err)
+	}
+	if _, ok := res.Set().Value("vcs.revision"); ok {
+		t.Error("newResource() expected no vcs.revision without build information")
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"tracer-test/pkg/clock"
+	"tracer-test/pkg/httpclient"
+	"tracer-test/pkg/logger"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Probe modes selectable with -probe
+const (
+	probeHTTP = "http"
+	probeTCP  = "tcp"
+	probeDNS  = "dns"
+)
+
+// validateProbeMode checks that mode is a supported probe mode
+func validateProbeMode(mode string) error {
+	switch mode {
+	case probeHTTP, probeTCP, probeDNS:
+		return nil
+	default:
+		return fmt.Errorf("unsupported probe mode %q", mode)
+	}
+}
+
+// probeHost parses target and returns its host and port
+func probeHost(target string) (string, string, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", "", err
+	}
+	if u.Hostname() == "" {
+		return "", "", fmt.Errorf("missing host in %q", target)
+	}
+	host, port := httpclient.HostPort(u)
+	return host, port, nil
+}
+
+// probeTCPConnect dials the target's host and port without an HTTP exchange
+// and records the connect latency on a tcp.probe span
+func probeTCPConnect(ctx context.Context, clk clock.Clock, log *logger.Logger, tracer trace.Tracer, target string, timeout time.Duration, requestCount int) requestResult {
+	ctx, span := tracer.Start(ctx, "tcp.probe",
+		trace.WithAttributes(
+			attribute.String("request.target_url", target),
+			attribute.Int("request.count", requestCount),
+		))
+	defer span.End()
+
+	sampled := span.SpanContext().IsSampled()
+	traceCtx := log.WithTraceContext(
+		span.SpanContext().TraceID().String(),
+		span.SpanContext().SpanID().String(),
+	).With(zap.Bool("sampled", sampled))
+	traceID := span.SpanContext().TraceID().String()
+
+	host, port, err := probeHost(target)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		traceCtx.Error("TCP probe failed", zap.Error(err))
+		return requestResult{traceID: traceID, sampled: sampled, err: err}
+	}
+
+	address := net.JoinHostPort(host, port)
+	span.SetAttributes(
+		attribute.String("tcp.host", host),
+		attribute.String("tcp.port", port),
+	)
+
+	dialer := &net.Dialer{Timeout: timeout}
+	start := clk.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	duration := clk.Now().Sub(start)
+
+	span.SetAttributes(
+		attribute.Int64("tcp.duration_ms", duration.Milliseconds()),
+		attribute.Bool("tcp.probe.success", err == nil),
+	)
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		traceCtx.Error("TCP probe failed",
+			zap.String("address", address),
+			zap.Duration("duration", duration),
+			zap.Error(err))
+		return requestResult{duration: duration, traceID: traceID, sampled: sampled, err: err}
+	}
+	defer conn.Close()
+
+	span.SetAttributes(
+		attribute.String("tcp.local_addr", conn.LocalAddr().String()),
+		attribute.String("tcp.remote_addr", conn.RemoteAddr().String()),
+	)
+	span.SetStatus(codes.Ok, "")
+	traceCtx.Info("TCP probe succeeded",
+		zap.String("address", address),
+		zap.Duration("duration", duration))
+
+	return requestResult{success: true, duration: duration, traceID: traceID, sampled: sampled}
+}
+
+// probeDNSResolve resolves the target's hostname without connecting and
+// records the resolution latency and addresses on a dns.probe span
+func probeDNSResolve(ctx context.Context, clk clock.Clock, log *logger.Logger, tracer trace.Tracer, target string, timeout time.Duration, requestCount int) requestResult {
+	ctx, span := tracer.Start(ctx, "dns.probe",
+		trace.WithAttributes(
+			attribute.String("request.target_url", target),
+			attribute.Int("request.count", requestCount),
+		))
+	defer span.End()
+
+	sampled := span.SpanContext().IsSampled()
+	traceCtx := log.WithTraceContext(
+		span.SpanContext().TraceID().String(),
+		span.SpanContext().SpanID().String(),
+	).With(zap.Bool("sampled", sampled))
+	traceID := span.SpanContext().TraceID().String()
+
+	host, _, err := probeHost(target)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		traceCtx.Error("DNS probe failed", zap.Error(err))
+		return requestResult{traceID: traceID, sampled: sampled, err: err}
+	}
+	span.SetAttributes(attribute.String("dns.hostname", host))
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := clk.Now()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	duration := clk.Now().Sub(start)
+
+	span.SetAttributes(
+		attribute.Int64("dns.duration_ms", duration.Milliseconds()),
+		attribute.Bool("dns.probe.success", err == nil),
+	)
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		traceCtx.Error("DNS probe failed",
+			zap.String("hostname", host),
+			zap.Duration("duration", duration),
+			zap.Error(err))
+		return requestResult{duration: duration, traceID: traceID, sampled: sampled, err: err}
+	}
+
+	addresses := make([]string, len(addrs))
+	for i, addr := range addrs {
+		addresses[i] = addr.String()
+	}
+	span.SetAttributes(attribute.StringSlice("dns.addresses", addresses))
+	span.SetStatus(codes.Ok, "")
+	traceCtx.Info("DNS probe succeeded",
+		zap.String("hostname", host),
+		zap.Strings("addresses", addresses),
+		zap.Duration("duration", duration))
+
+	return requestResult{success: true, duration: duration, traceID: traceID, sampled: sampled}
+}
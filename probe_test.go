@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"tracer-test/pkg/clock"
+	"tracer-test/pkg/logger"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestProbeTCPConnect_Success(t *testing.T) {
+	// Create a local listener
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer listener.Close()
+
+	// Create a test logger with observer
+	core, recorded := observer.New(zapcore.InfoLevel)
+	log := &logger.Logger{Logger: zap.New(core)}
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	otelTracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	target := "http://" + listener.Addr().String()
+	result := probeTCPConnect(context.Background(), clock.Real{}, log, otelTracer, target, time.Second, 1)
+	if result.err != nil || !result.success {
+		t.Fatalf("probeTCPConnect() = %+v, expected success", result)
+	}
+
+	// Check the probe span
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "tcp.probe" {
+		t.Fatalf("Expected a single tcp.probe span, got %d spans", len(spans))
+	}
+	success := false
+	for _, attr := range spans[0].Attributes() {
+		if attr.Key == "tcp.probe.success" {
+			success = attr.Value.AsBool()
+		}
+	}
+	if !success {
+		t.Error("Expected tcp.probe span to have tcp.probe.success=true")
+	}
+
+	if recorded.FilterMessage("TCP probe succeeded").Len() == 0 {
+		t.Error("Expected to find 'TCP probe succeeded' log message")
+	}
+}
+
+func TestProbeTCPConnect_Refused(t *testing.T) {
+	// Reserve a port and close it so connections are refused
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	// Create a test logger with observer
+	core, recorded := observer.New(zapcore.InfoLevel)
+	log := &logger.Logger{Logger: zap.New(core)}
+
+	otelTracer := sdktrace.NewTracerProvider().Tracer("test")
+
+	result := probeTCPConnect(context.Background(), clock.Real{}, log, otelTracer, "http://"+addr, time.Second, 1)
+	if result.err == nil || result.success {
+		t.Errorf("probeTCPConnect() = %+v, expected failure", result)
+	}
+	if recorded.FilterMessage("TCP probe failed").Len() == 0 {
+		t.Error("Expected to find 'TCP probe failed' log message")
+	}
+}
+
+func TestValidateProbeMode(t *testing.T) {
+	for _, mode := range []string{probeHTTP, probeTCP} {
+		if err := validateProbeMode(mode); err != nil {
+			t.Errorf("validateProbeMode(%q) error = %v", mode, err)
+		}
+	}
+	if err := validateProbeMode("udp"); err == nil {
+		t.Error("validateProbeMode(\"udp\") expected error")
+	}
+}
+
+func TestProbeDNSResolve_Localhost(t *testing.T) {
+	// Create a test logger with observer
+	core, recorded := observer.New(zapcore.InfoLevel)
+	log := &logger.Logger{Logger: zap.New(core)}
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	otelTracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	result := probeDNSResolve(context.Background(), clock.Real{}, log, otelTracer, "http://localhost:8080", time.Second, 1)
+	if result.err != nil || !result.success {
+		t.Fatalf("probeDNSResolve() = %+v, expected success", result)
+	}
+
+	// Check the probe span
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "dns.probe" {
+		t.Fatalf("Expected a single dns.probe span, got %d spans", len(spans))
+	}
+	var addresses []string
+	for _, attr := range spans[0].Attributes() {
+		if attr.Key == "dns.addresses" {
+			addresses = attr.Value.AsStringSlice()
+		}
+	}
+	if len(addresses) == 0 {
+		t.Error("Expected dns.probe span to record resolved addresses")
+	}
+
+	if recorded.FilterMessage("DNS probe succeeded").Len() == 0 {
+		t.Error("Expected to find 'DNS probe succeeded' log message")
+	}
+}
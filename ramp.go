@@ -0,0 +1,53 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// rampUp eases the request interval linearly from a start interval down to
+// the target interval over a fixed duration, so load builds gradually
+type rampUp struct {
+	mu       sync.Mutex
+	start    time.Duration
+	target   time.Duration
+	duration time.Duration
+	began    time.Time
+}
+
+// newRampUp creates a ramp from start to target over duration. It begins
+// when begin is called.
+func newRampUp(start, target, duration time.Duration) *rampUp {
+	return &rampUp{start: start, target: target, duration: duration}
+}
+
+// begin starts the ramp at now
+func (r *rampUp) begin(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.began = now
+}
+
+// at returns the interval at now, which stays at the target once the ramp
+// is over
+func (r *rampUp) at(now time.Time) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	elapsed := now.Sub(r.began)
+	if elapsed >= r.duration {
+		return r.target
+	}
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	progress := float64(elapsed) / float64(r.duration)
+	return r.start - time.Duration(float64(r.start-r.target)*progress)
+}
+
+// setTarget changes the interval the ramp ends at, e.g. after PUT /interval
+func (r *rampUp) setTarget(target time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.target = target
+}
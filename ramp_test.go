@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRampUp(t *testing.T) {
+	began := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := newRampUp(time.Second, 100*time.Millisecond, 10*time.Second)
+	r.begin(began)
+
+	// The interval decreases linearly over the ramp and then stays at the target
+	tests := []struct {
+		elapsed  time.Duration
+		expected time.Duration
+	}{
+		{0, time.Second},
+		{5 * time.Second, 550 * time.Millisecond},
+		{9 * time.Second, 190 * time.Millisecond},
+		{10 * time.Second, 100 * time.Millisecond},
+		{time.Minute, 100 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := r.at(began.Add(tt.elapsed)); got != tt.expected {
+			t.Errorf("at(+%s) = %s, expected %s", tt.elapsed, got, tt.expected)
+		}
+	}
+
+	// A new target is reached at the end of the ramp
+	r.setTarget(200 * time.Millisecond)
+	if got := r.at(began.Add(10 * time.Second)); got != 200*time.Millisecond {
+		t.Errorf("at(+10s) after setTarget = %s, expected %s", got, 200*time.Millisecond)
+	}
+}
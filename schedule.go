@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// cronParser accepts standard five-field cron expressions, an optional
+// leading seconds field, and descriptors such as @hourly or @every 30s
+var cronParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour |
+	cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// parseSchedule validates a cron expression
+func parseSchedule(expr string) (cron.Schedule, error) {
+	schedule, err := cronParser.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", expr, err)
+	}
+	return schedule, nil
+}
+
+// scheduleTicker delivers ticks on a cron schedule. Ticks that fire while
+// the loop is still busy with a cycle are dropped, like time.Ticker.
+type scheduleTicker struct {
+	cron *cron.Cron
+	c    chan time.Time
+}
+
+// newScheduleTicker starts delivering ticks on schedule
+func newScheduleTicker(schedule cron.Schedule) *scheduleTicker {
+	t := &scheduleTicker{
+		cron: cron.New(cron.WithParser(cronParser)),
+		c:    make(chan time.Time, 1),
+	}
+	t.cron.Schedule(schedule, cron.FuncJob(func() {
+		select {
+		case t.c <- time.Now():
+		default:
+		}
+	}))
+	t.cron.Start()
+	return t
+}
+
+// C returns the channel on which ticks are delivered
+func (t *scheduleTicker) C() <-chan time.Time {
+	return t.c
+}
+
+// Stop stops the scheduler, waiting for a tick being delivered to finish
+func (t *scheduleTicker) Stop() {
+	<-t.cron.Stop().Done()
+}
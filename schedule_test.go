@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{"*/5 9-17 * * 1-5", false},
+		{"*/2 * * * * *", false},
+		{"@hourly", false},
+		{"@every 30s", false},
+		{"", true},
+		{"every day", true},
+		{"61 * * * *", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if _, err := parseSchedule(tt.expr); (err != nil) != tt.wantErr {
+				t.Errorf("parseSchedule(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApp_RunOnSchedule(t *testing.T) {
+	// Create a stub target counting requests
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var logs syncBuffer
+	app, err := NewApp(Settings{
+		URL: server.URL,
+		// The interval is ignored in favor of the every-second schedule
+		Interval:    time.Hour,
+		Schedule:    "* * * * * *",
+		Probe:       probeHTTP,
+		ServiceName: "test-service",
+		LogLevel:    "info",
+		LogFormat:   "json",
+		LogOutput:   &logs,
+		DisableOTLP: true,
+	})
+	if err != nil {
+		t.Fatalf("NewApp() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- app.Run(ctx)
+	}()
+
+	deadline := time.After(5 * time.Second)
+	for hits.Load() < 2 {
+		select {
+		case <-deadline:
+			t.Fatalf("Schedule fired %d requests in 5s, expected 2", hits.Load())
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	cancel()
+
+	select {
+	case err := <-runErr:
+		if err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not return after cancellation")
+	}
+
+	// Shutdown stops the scheduler cleanly
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+	if err := app.Shutdown(shutdownCtx); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+}
+
+func TestFlagWasSet(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Duration("interval", time.Second, "")
+	fs.String("schedule", "", "")
+	if err := fs.Parse([]string{"-schedule", "@hourly"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if !flagWasSet(fs, "schedule") {
+		t.Error("flagWasSet(schedule) = false, expected true")
+	}
+	if flagWasSet(fs, "interval") {
+		t.Error("flagWasSet(interval) = true, expected false for a defaulted flag")
+	}
+}
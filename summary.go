@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"tracer-test/pkg/httpclient"
+	"tracer-test/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// runStats accumulates request outcomes for the end-of-run summary
+type runStats struct {
+	requests  int
+	successes int
+	failures  int
+	errors    map[string]int
+	bytes     int64
+	started   time.Time
+}
+
+// newRunStats creates empty run statistics starting at started
+func newRunStats(started time.Time) *runStats {
+	return &runStats{
+		errors:  make(map[string]int),
+		started: started,
+	}
+}
+
+// record adds a cycle's outcome to the statistics
+func (s *runStats) record(result requestResult) {
+	s.requests++
+	s.bytes += int64(result.requestSize + result.responseSize)
+
+	if result.success {
+		s.successes++
+		return
+	}
+	s.failures++
+
+	switch {
+	case result.err != nil:
+		s.errors[httpclient.ErrorCategory(result.err)]++
+	case result.statusCode != 0:
+		s.errors[fmt.Sprintf("http_%d", result.statusCode)]++
+	default:
+		s.errors["other"]++
+	}
+}
+
+// logSummary emits the run_summary log entry
+func (s *runStats) logSummary(log *logger.Logger, now time.Time) {
+	categories := make([]string, 0, len(s.errors))
+	for category := range s.errors {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	errorFields := make([]zap.Field, 0, len(categories))
+	for _, category := range categories {
+		errorFields = append(errorFields, zap.Int(category, s.errors[category]))
+	}
+
+	log.Info("run_summary",
+		zap.Int("total_requests", s.requests),
+		zap.Int("successes", s.successes),
+		zap.Int("failures", s.failures),
+		zap.Dict("errors_by_category", errorFields...),
+		zap.Int64("total_bytes", s.bytes),
+		zap.Duration("run_duration", now.Sub(s.started)))
+}
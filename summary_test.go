@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"tracer-test/pkg/httpclient"
+	"tracer-test/pkg/logger"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRunStats_LogSummary(t *testing.T) {
+	// Create a test logger with observer
+	core, recorded := observer.New(zapcore.InfoLevel)
+	log := &logger.Logger{Logger: zap.New(core)}
+
+	start := time.Date(2024, 1, 15, 10, 30, 45, 0, time.UTC)
+	stats := newRunStats(start)
+
+	// Drive a short run with successes, an error status and a body timeout
+	stats.record(requestResult{success: true, statusCode: 200, requestSize: 10, responseSize: 100})
+	stats.record(requestResult{success: true, statusCode: 200, responseSize: 50})
+	stats.record(requestResult{statusCode: 503, responseSize: 20})
+	stats.record(requestResult{statusCode: 200, err: fmt.Errorf("%w after 1s", httpclient.ErrBodyReadTimeout)})
+	stats.record(requestResult{err: errors.New("boom")})
+
+	stats.logSummary(log, start.Add(time.Minute))
+
+	entries := recorded.FilterMessage("run_summary").All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 run_summary log entry, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+
+	expected := map[string]interface{}{
+		"total_requests": int64(5),
+		"successes":      int64(2),
+		"failures":       int64(3),
+		"total_bytes":    int64(180),
+		"run_duration":   time.Minute,
+	}
+	for key, want := range expected {
+		if fields[key] != want {
+			t.Errorf("run_summary %s = %v, expected %v", key, fields[key], want)
+		}
+	}
+
+	byCategory, ok := fields["errors_by_category"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("run_summary errors_by_category = %T, expected a map", fields["errors_by_category"])
+	}
+	for _, category := range []string{"http_503", "body_read_timeout", "other"} {
+		if byCategory[category] != int64(1) {
+			t.Errorf("errors_by_category[%s] = %v, expected 1", category, byCategory[category])
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// readURLs reads newline-separated URLs from r, skipping empty lines and
+// lines starting with #
+func readURLs(r io.Reader) ([]string, error) {
+	var urls []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		urls = append(urls, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read URLs: %w", err)
+	}
+	return urls, nil
+}
+
+// targetFromStdin returns the single target URL piped in on stdin. Each run
+// probes one target, so more than one URL is rejected.
+func targetFromStdin(r io.Reader) (string, error) {
+	urls, err := readURLs(r)
+	if err != nil {
+		return "", err
+	}
+	switch len(urls) {
+	case 0:
+		return "", fmt.Errorf("no URL on stdin")
+	case 1:
+		return urls[0], nil
+	default:
+		return "", fmt.Errorf("got %d URLs on stdin, only a single target is supported", len(urls))
+	}
+}
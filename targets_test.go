@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadURLs(t *testing.T) {
+	stdin := strings.NewReader(`# generated targets
+https://api.example.com/health
+
+  https://api.example.com/ready  
+# https://disabled.example.com
+`)
+
+	urls, err := readURLs(stdin)
+	if err != nil {
+		t.Fatalf("readURLs() error = %v", err)
+	}
+	expected := []string{"https://api.example.com/health", "https://api.example.com/ready"}
+	if strings.Join(urls, " ") != strings.Join(expected, " ") {
+		t.Errorf("readURLs() = %v, expected %v", urls, expected)
+	}
+}
+
+func TestTargetFromStdin(t *testing.T) {
+	tests := []struct {
+		name     string
+		stdin    string
+		expected string
+		wantErr  bool
+	}{
+		{"single URL", "# target\nhttps://example.com\n", "https://example.com", false},
+		{"empty", "\n# nothing\n", "", true},
+		{"several URLs", "https://a.example.com\nhttps://b.example.com\n", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := targetFromStdin(strings.NewReader(tt.stdin))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("targetFromStdin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if target != tt.expected {
+				t.Errorf("targetFromStdin() = %q, expected %q", target, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// intervalTicker is a ticker whose interval can be changed while the
+// request loop is running
+type intervalTicker struct {
+	mu       sync.Mutex
+	interval time.Duration
+	ticker   *time.Ticker
+}
+
+// newIntervalTicker starts a ticker firing every interval
+func newIntervalTicker(interval time.Duration) *intervalTicker {
+	return &intervalTicker{
+		interval: interval,
+		ticker:   time.NewTicker(interval),
+	}
+}
+
+// C returns the channel on which ticks are delivered
+func (t *intervalTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+// Interval returns the current interval
+func (t *intervalTicker) Interval() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.interval
+}
+
+// SetInterval changes the interval; the next tick fires one full new
+// interval from now
+func (t *intervalTicker) SetInterval(interval time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.interval = interval
+	t.ticker.Reset(interval)
+}
+
+// Stop stops the ticker
+func (t *intervalTicker) Stop() {
+	t.ticker.Stop()
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIntervalTicker_SetInterval(t *testing.T) {
+	ticker := newIntervalTicker(time.Hour)
+	defer ticker.Stop()
+
+	ticker.SetInterval(20 * time.Millisecond)
+	if got := ticker.Interval(); got != 20*time.Millisecond {
+		t.Errorf("Interval() = %s, expected %s", got, 20*time.Millisecond)
+	}
+
+	// Subsequent ticks should use the new interval rather than the hour
+	for i := 0; i < 3; i++ {
+		select {
+		case <-ticker.C():
+		case <-time.After(time.Second):
+			t.Fatalf("Tick %d not received after SetInterval", i+1)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// traceIDWriter writes one greppable line per request cycle, separate from
+// the structured logs:
+//
+//	TRACE <trace_id> <url> <status>
+type traceIDWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// newTraceIDWriter creates a trace ID writer writing to w
+func newTraceIDWriter(w io.Writer) *traceIDWriter {
+	return &traceIDWriter{w: w}
+}
+
+// write emits the line for a completed cycle. status is the HTTP status
+// code, or "error" when no response was received.
+func (t *traceIDWriter) write(url string, result requestResult) error {
+	if result.traceID == "" {
+		return nil
+	}
+
+	status := "error"
+	if result.statusCode != 0 {
+		status = strconv.Itoa(result.statusCode)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, err := fmt.Fprintf(t.w, "TRACE %s %s %s\n", result.traceID, url, status)
+	return err
+}
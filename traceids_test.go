@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"tracer-test/pkg/httpclient"
+	"tracer-test/pkg/logger"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestTraceIDWriter(t *testing.T) {
+	// Create a test server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Create a test logger with observer
+	core, _ := observer.New(zapcore.InfoLevel)
+	log := &logger.Logger{Logger: zap.New(core)}
+
+	// Create a real tracer so cycles have trace IDs
+	otelTracer := sdktrace.NewTracerProvider().Tracer("test")
+
+	client := httpclient.New(httpclient.Config{
+		Timeout: 5 * time.Second,
+	}, log.Logger, otelTracer)
+	defer client.Close()
+
+	var out bytes.Buffer
+	writer := newTraceIDWriter(&out)
+	for i := 1; i <= 3; i++ {
+		result := makeRequest(context.Background(), client, log, otelTracer, server.URL, i)
+		if err := writer.write(server.URL, result); err != nil {
+			t.Fatalf("write() error = %v", err)
+		}
+	}
+
+	// Check for one well-formed line per request
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Wrote %d lines, expected 3: %q", len(lines), out.String())
+	}
+	pattern := regexp.MustCompile(`^TRACE [0-9a-f]{32} ` + regexp.QuoteMeta(server.URL) + ` 200$`)
+	for _, line := range lines {
+		if !pattern.MatchString(line) {
+			t.Errorf("Line %q does not match %s", line, pattern)
+		}
+	}
+}
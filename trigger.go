@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"tracer-test/pkg/health"
+	"tracer-test/pkg/httpclient"
+	"tracer-test/pkg/logger"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// triggerSnippetSize bounds the response body returned by /trigger overrides
+const triggerSnippetSize = 512
+
+// makeTriggerRequest sends the one-off request described by a /trigger body
+// under a request.trigger span and reports its outcome
+func makeTriggerRequest(ctx context.Context, client *httpclient.Client, log *logger.Logger, tracer trace.Tracer, override *health.TriggerRequest) health.TriggerResult {
+	// Reuse the caller's request ID or generate one
+	requestID, ok := httpclient.RequestIDFromContext(ctx)
+	if !ok {
+		requestID = uuid.NewString()
+		ctx = httpclient.WithRequestID(ctx, requestID)
+	}
+
+	ctx, span := tracer.Start(ctx, "request.trigger",
+		trace.WithAttributes(
+			attribute.String("request.method", override.Method),
+			attribute.String("request.target_url", client.ScrubURL(override.URL)),
+			attribute.String("request.id", requestID),
+		))
+	defer span.End()
+
+	traceID := span.SpanContext().TraceID().String()
+	traceCtx := log.WithTraceContext(traceID, span.SpanContext().SpanID().String()).
+		With(zap.String("request_id", requestID))
+
+	header := make(http.Header, len(override.Headers))
+	for name, value := range override.Headers {
+		header.Set(name, value)
+	}
+
+	clk := client.Clock()
+	start := clk.Now()
+
+	resp, err := client.Do(ctx, override.Method, override.URL, header)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		traceCtx.Error("Triggered request failed", zap.Error(err))
+		return health.TriggerResult{DurationMs: clk.Now().Sub(start).Milliseconds(), TraceID: traceID, Error: err.Error()}
+	}
+
+	body, err := client.ReadBody(ctx, resp)
+	result := health.TriggerResult{
+		StatusCode:      resp.StatusCode,
+		DurationMs:      clk.Now().Sub(start).Milliseconds(),
+		TraceID:         traceID,
+		ResponseSnippet: responseSnippet(body),
+	}
+	if err != nil {
+		traceCtx.Error("Failed to read triggered response body", zap.Error(err))
+		result.Error = err.Error()
+		return result
+	}
+
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+	if client.IsErrorStatus(resp.StatusCode) {
+		span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", resp.StatusCode))
+	} else {
+		span.SetStatus(codes.Ok, "")
+	}
+
+	traceCtx.Info("Triggered request completed",
+		zap.String("method", override.Method),
+		zap.String("url", client.ScrubURL(override.URL)),
+		zap.Int("status_code", resp.StatusCode),
+		zap.Int64("duration_ms", result.DurationMs))
+
+	return result
+}
+
+// responseSnippet returns the start of body as valid UTF-8
+func responseSnippet(body []byte) string {
+	if len(body) > triggerSnippetSize {
+		body = body[:triggerSnippetSize]
+	}
+	return strings.ToValidUTF8(string(body), "")
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tracer-test/pkg/health"
+	"tracer-test/pkg/httpclient"
+	"tracer-test/pkg/logger"
+
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestMakeTriggerRequest_CallerContext(t *testing.T) {
+	// Create a test server recording the request ID header
+	var requestID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID = r.Header.Get(httpclient.RequestIDHeader)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Create a test logger with observer
+	core, _ := observer.New(zapcore.InfoLevel)
+	log := &logger.Logger{Logger: zap.New(core)}
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	otelTracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := httpclient.New(httpclient.Config{
+		Timeout: 5 * time.Second,
+	}, log.Logger, otelTracer)
+	defer client.Close()
+
+	// The caller's trace context and request ID, as extracted by /trigger
+	ctx := propagation.TraceContext{}.Extract(context.Background(), propagation.HeaderCarrier(http.Header{
+		"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+	}))
+	ctx = httpclient.WithRequestID(ctx, "caller-42")
+
+	result := makeTriggerRequest(ctx, client, log, otelTracer, &health.TriggerRequest{
+		Method: http.MethodGet,
+		URL:    server.URL,
+	})
+
+	if result.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("TraceID = %q, expected the caller's trace ID", result.TraceID)
+	}
+	for _, s := range recorder.Ended() {
+		if s.Name() == "request.trigger" && s.Parent().SpanID().String() != "00f067aa0ba902b7" {
+			t.Errorf("request.trigger parent = %s, expected the caller's span", s.Parent().SpanID())
+		}
+	}
+	if requestID != "caller-42" {
+		t.Errorf("X-Request-ID = %q, expected the caller's request ID", requestID)
+	}
+}
+
+func TestMakeTriggerRequest_Override(t *testing.T) {
+	// Create a test server standing in for the override target
+	var method, path, custom string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path, custom = r.Method, r.URL.Path, r.Header.Get("X-Custom")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"created":true}`))
+	}))
+	defer server.Close()
+
+	// Create a test logger with observer
+	core, _ := observer.New(zapcore.InfoLevel)
+	log := &logger.Logger{Logger: zap.New(core)}
+
+	// Create a recording tracer
+	recorder := tracetest.NewSpanRecorder()
+	otelTracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	client := httpclient.New(httpclient.Config{
+		Timeout: 5 * time.Second,
+	}, log.Logger, otelTracer)
+	defer client.Close()
+
+	result := makeTriggerRequest(context.Background(), client, log, otelTracer, &health.TriggerRequest{
+		Method:  http.MethodPost,
+		URL:     server.URL + "/override",
+		Headers: map[string]string{"X-Custom": "yes"},
+	})
+
+	// Check the request hit the override URL
+	if method != http.MethodPost || path != "/override" || custom != "yes" {
+		t.Errorf("Target received %s %s (X-Custom=%q), expected POST /override (X-Custom=\"yes\")", method, path, custom)
+	}
+
+	// Check the outcome
+	if result.StatusCode != http.StatusCreated {
+		t.Errorf("StatusCode = %d, expected %d", result.StatusCode, http.StatusCreated)
+	}
+	if result.ResponseSnippet != `{"created":true}` {
+		t.Errorf("ResponseSnippet = %q, expected the response body", result.ResponseSnippet)
+	}
+
+	var traceID string
+	for _, s := range recorder.Ended() {
+		if s.Name() == "request.trigger" {
+			traceID = s.SpanContext().TraceID().String()
+		}
+	}
+	if traceID == "" || result.TraceID != traceID {
+		t.Errorf("TraceID = %q, expected request.trigger trace id %q", result.TraceID, traceID)
+	}
+}
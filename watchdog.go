@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"tracer-test/pkg/clock"
+)
+
+// watchdogGrace is added to the request timeout when computing the stall threshold
+const watchdogGrace = 5 * time.Second
+
+// watchdogCheckInterval is how often the watchdog looks for a stalled loop
+const watchdogCheckInterval = time.Second
+
+// stallThreshold returns how long the loop may go without activity before
+// it is considered stalled
+func stallThreshold(interval, timeout time.Duration) time.Duration {
+	threshold := 2 * interval
+	if t := timeout + watchdogGrace; t > threshold {
+		threshold = t
+	}
+	return threshold
+}
+
+// watchdog detects a request loop that has stopped making progress and
+// cancels the in-flight cycle so the loop can continue
+type watchdog struct {
+	clock     clock.Clock
+	threshold time.Duration
+	onStall   func(idle time.Duration)
+
+	mu           sync.Mutex
+	lastActivity time.Time
+	cancelCycle  context.CancelFunc
+}
+
+// newWatchdog creates a watchdog that calls onStall after threshold of inactivity
+func newWatchdog(clk clock.Clock, threshold time.Duration, onStall func(idle time.Duration)) *watchdog {
+	return &watchdog{
+		clock:        clk,
+		threshold:    threshold,
+		onStall:      onStall,
+		lastActivity: clk.Now(),
+	}
+}
+
+// begin records the start of a cycle and returns its cancellable context.
+// The returned function must be called when the cycle completes.
+func (w *watchdog) begin(ctx context.Context) (context.Context, func()) {
+	cycleCtx, cancel := context.WithCancel(ctx)
+
+	w.mu.Lock()
+	w.lastActivity = w.clock.Now()
+	w.cancelCycle = cancel
+	w.mu.Unlock()
+
+	return cycleCtx, func() {
+		w.mu.Lock()
+		w.lastActivity = w.clock.Now()
+		w.cancelCycle = nil
+		w.mu.Unlock()
+		cancel()
+	}
+}
+
+// touch records loop activity without starting a cycle, e.g. for ticks
+// skipped during maintenance
+func (w *watchdog) touch() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastActivity = w.clock.Now()
+}
+
+// setThreshold changes the inactivity threshold, e.g. after the request
+// interval changes
+func (w *watchdog) setThreshold(threshold time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.threshold = threshold
+}
+
+// check cancels the in-flight cycle when the loop has been idle for longer
+// than the threshold, reporting whether a stall was detected
+func (w *watchdog) check() bool {
+	w.mu.Lock()
+	idle := w.clock.Now().Sub(w.lastActivity)
+	if idle <= w.threshold {
+		w.mu.Unlock()
+		return false
+	}
+	cancel := w.cancelCycle
+	w.cancelCycle = nil
+	w.lastActivity = w.clock.Now()
+	w.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	if w.onStall != nil {
+		w.onStall(idle)
+	}
+	return true
+}
+
+// run checks for stalls every checkInterval until ctx is done
+func (w *watchdog) run(ctx context.Context, checkInterval time.Duration) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"tracer-test/pkg/clock"
+)
+
+func TestStallThreshold(t *testing.T) {
+	if got := stallThreshold(time.Minute, 10*time.Second); got != 2*time.Minute {
+		t.Errorf("stallThreshold() = %s, expected %s", got, 2*time.Minute)
+	}
+	if got := stallThreshold(time.Second, 10*time.Second); got != 10*time.Second+watchdogGrace {
+		t.Errorf("stallThreshold() = %s, expected %s", got, 10*time.Second+watchdogGrace)
+	}
+}
+
+func TestWatchdog_CancelsStalledCycle(t *testing.T) {
+	fakeClock := clock.NewFake(time.Date(2024, 1, 15, 10, 30, 45, 0, time.UTC))
+
+	stalls := 0
+	w := newWatchdog(fakeClock, 30*time.Second, func(idle time.Duration) {
+		stalls++
+	})
+
+	// Simulate a makeRequest that hangs until its context is cancelled
+	cycleCtx, done := w.begin(context.Background())
+	finished := make(chan struct{})
+	go func() {
+		<-cycleCtx.Done()
+		done()
+		close(finished)
+	}()
+
+	// No stall before the threshold
+	fakeClock.Advance(20 * time.Second)
+	if w.check() {
+		t.Error("check() detected a stall before the threshold")
+	}
+
+	// Stall after the threshold
+	fakeClock.Advance(20 * time.Second)
+	if !w.check() {
+		t.Fatal("check() did not detect the stall")
+	}
+	if stalls != 1 {
+		t.Errorf("onStall called %d times, expected 1", stalls)
+	}
+
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("Stalled cycle was not cancelled")
+	}
+
+	// Activity resets the watchdog
+	if w.check() {
+		t.Error("check() detected a stall right after recovery")
+	}
+}